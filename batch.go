@@ -0,0 +1,90 @@
+package tombstreams
+
+import (
+	"time"
+
+	"gopkg.in/tomb.v2"
+)
+
+// Batch groups the incoming elements into []interface{} batches.
+// A batch is emitted once it holds maxSize elements or maxWait has passed
+// since its first element, whichever comes first.
+//
+// in  -- 1 -- 2 -- 3 ------------ 4 ----------------- 5 --
+//        |    |    |              |                   |
+//    [- maxSize = 3 -]      [---- maxWait ----]
+//                    |                        |       |
+// out -------------- [1 2 3] ---------------- [4] --- [5]
+type Batch struct {
+	stage
+	maxSize uint
+	maxWait time.Duration
+}
+
+// Verify Batch satisfies the Flow interface.
+var _ Flow = (*Batch)(nil)
+
+// NewBatch returns a new Batch instance.
+// maxSize is the maximum number of elements in a batch.
+// maxWait is the maximum time the first element of a batch waits to be emitted.
+func NewBatch(t *tomb.Tomb, maxSize uint, maxWait time.Duration, opts ...Option) *Batch {
+	if maxSize == 0 {
+		panic("Batch maxSize must be positive")
+	}
+	batch := &Batch{
+		newStage(t, newOptions("Batch", opts)),
+		maxSize,
+		maxWait,
+	}
+	if t.Alive() {
+		t.Go(batch.doStream)
+	}
+	return batch
+}
+
+func (b *Batch) doStream() error {
+	defer close(b.out)
+
+	timer := time.NewTimer(b.maxWait)
+	defer timer.Stop()
+	var expired <-chan time.Time
+	stopTimer := func() {
+		if !timer.Stop() {
+			// Drain a tick that fired but was not received.
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		expired = nil
+	}
+	stopTimer()
+
+	buffer := make([]interface{}, 0, b.maxSize)
+	for {
+		select {
+		case elem, ok := <-b.in:
+			if !ok {
+				b.emitWindow(buffer)
+				return nil
+			}
+			if len(buffer) == 0 {
+				timer.Reset(b.maxWait)
+				expired = timer.C
+			}
+			buffer = append(buffer, elem)
+			if uint(len(buffer)) < b.maxSize {
+				continue
+			}
+			stopTimer()
+		case <-expired:
+			expired = nil
+		case <-b.t.Dying():
+			return nil
+		}
+		if !b.emitWindow(buffer) {
+			return nil
+		}
+		buffer = make([]interface{}, 0, b.maxSize)
+	}
+}
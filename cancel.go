@@ -0,0 +1,67 @@
+package tombstreams
+
+import (
+	"sync"
+
+	"gopkg.in/tomb.v2"
+)
+
+// cancellation is the signal a downstream stage sends to tell that it does
+// not need any more elements. It implements Cancellable.
+type cancellation struct {
+	once sync.Once
+	done chan struct{}
+}
+
+func newCancellation() *cancellation {
+	return &cancellation{done: make(chan struct{})}
+}
+
+// Cancel tells the stage that no more elements are needed downstream.
+// It is safe to call it several times and from several goroutines.
+func (c *cancellation) Cancel() {
+	c.once.Do(func() {
+		close(c.done)
+	})
+}
+
+// Cancelled returns a channel that is closed once the stage is cancelled.
+func (c *cancellation) Cancelled() <-chan struct{} {
+	return c.done
+}
+
+// cancelledOf returns the cancellation channel of x, or nil if x is not
+// Cancellable. A nil channel blocks, so it can be selected on either way.
+func cancelledOf(x interface{}) <-chan struct{} {
+	if c, ok := x.(Cancellable); ok {
+		return c.Cancelled()
+	}
+	return nil
+}
+
+// cancelUpstream cancels the outlet if it is Cancellable.
+func cancelUpstream(outlet Outlet) {
+	if c, ok := outlet.(Cancellable); ok {
+		c.Cancel()
+	}
+}
+
+// allCancelled returns a channel that is closed once every flow is cancelled.
+// It is never closed if done is closed first.
+func allCancelled(t *tomb.Tomb, flows []Flow, done <-chan struct{}) <-chan struct{} {
+	all := make(chan struct{})
+	t.Go(func() error {
+		for _, flow := range flows {
+			select {
+			case <-cancelledOf(flow):
+			case <-done:
+				return nil
+			case <-t.Dying():
+				return nil
+			}
+		}
+		close(all)
+		return nil
+	})
+	return all
+}
@@ -0,0 +1,139 @@
+package tombstreams
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"gopkg.in/tomb.v2"
+)
+
+// DeadLetter is the envelope of an element that a stage failed to process.
+// It is sent to the inlet given to OnErrorDeadLetter, so failures can be
+// stored for reprocessing instead of being lost.
+type DeadLetter struct {
+	// Element is the original input element.
+	Element interface{}
+	// Err is the error returned by the last attempt.
+	Err error
+	// Stage is the name of the stage, see WithName.
+	Stage string
+	// Attempts is the number of times the stage function was called.
+	Attempts uint
+	// Time is when the last attempt failed.
+	Time time.Time
+}
+
+// Error implements the error interface, so a DeadLetter can be printed or wrapped.
+func (dl DeadLetter) Error() string {
+	return fmt.Sprintf("%s: element %v failed after %d attempt(s): %v", dl.Stage, dl.Element, dl.Attempts, dl.Err)
+}
+
+// Unwrap returns the error of the last attempt.
+func (dl DeadLetter) Unwrap() error {
+	return dl.Err
+}
+
+type errorStrategy int
+
+const (
+	stopOnError errorStrategy = iota
+	resumeOnError
+	deadLetterOnError
+)
+
+type errorPolicy struct {
+	strategy   errorStrategy
+	retry      RetryPolicy
+	deadLetter Inlet
+}
+
+// OnErrorStop kills the tomb with the first error returned by the stage
+// function, stopping the whole pipeline. This is the default.
+// The OnError options are supported by the stages calling a function on every
+// element; the other stages panic if one is set.
+func OnErrorStop() Option {
+	return func(o *options) {
+		o.onError.strategy = stopOnError
+		o.features |= errorPolicyFeature
+		o.onError.deadLetter = nil
+	}
+}
+
+// OnErrorResume discards an element the stage function failed on and
+// continues with the next one.
+func OnErrorResume() Option {
+	return func(o *options) {
+		o.onError.strategy = resumeOnError
+		o.features |= errorPolicyFeature
+		o.onError.deadLetter = nil
+	}
+}
+
+// OnErrorRetry calls the stage function again on failure according to the policy.
+// Once the attempts are exhausted, or the error is not retryable, the element
+// is handled by the stop, resume or dead-letter strategy, stop being the default.
+func OnErrorRetry(policy RetryPolicy) Option {
+	return func(o *options) {
+		o.onError.retry = policy
+		o.features |= errorPolicyFeature
+	}
+}
+
+// OnErrorDeadLetter sends a DeadLetter for every element the stage function
+// failed on to the given inlet and continues with the next element.
+// The inlet can be a Sink, or a Flow such as a PassThrough to process the
+// dead letters as an Outlet. Once that inlet is cancelled, the dead letters
+// are discarded.
+// The stage closes the inlet once it has finished, so every stage needs its
+// own dead-letter inlet; use Merge to gather them.
+func OnErrorDeadLetter(inlet Inlet) Option {
+	return func(o *options) {
+		o.onError.strategy = deadLetterOnError
+		o.features |= errorPolicyFeature
+		o.onError.deadLetter = inlet
+	}
+}
+
+// apply calls fn with elem and handles a failure according to the policy.
+// The bool result is false if the element must be skipped, and a non-nil
+// error must be returned from the worker to kill the tomb. Retries stop once
+// the tomb is dying or the stage is cancelled, see Cancelled.
+func apply[R any](t *tomb.Tomb, cancelled <-chan struct{}, o *options, elem interface{}, fn func(interface{}) (R, error)) (R, bool, error) {
+	var zero R
+	p := &o.onError
+	res, attempts, err := retry(t, cancelled, &p.retry, func() (R, error) {
+		return fn(elem)
+	})
+	if err == nil {
+		return res, true, nil
+	}
+	if err == tomb.ErrDying {
+		return zero, false, nil
+	}
+	atomic.AddUint64(&o.counters.failed, 1)
+
+	switch p.strategy {
+	case resumeOnError:
+		return zero, false, nil
+	case deadLetterOnError:
+		// A cancelled dead-letter inlet needs no more dead letters.
+		select {
+		case p.deadLetter.In() <- DeadLetter{elem, err, o.name, attempts, time.Now()}:
+		case <-cancelledOf(p.deadLetter):
+		case <-cancelled:
+		case <-t.Dying():
+		}
+		return zero, false, nil
+	default:
+		return zero, false, err
+	}
+}
+
+// close closes the dead-letter inlet of the policy, if there is one.
+// It must be called once no worker of the stage can send to it anymore.
+func (p *errorPolicy) close() {
+	if p.deadLetter != nil {
+		close(p.deadLetter.In())
+	}
+}
@@ -5,8 +5,6 @@ import (
 	"fmt"
 	"time"
 
-	"gopkg.in/tomb.v2"
-
 	"github.com/artificial-james/tombstreams"
 )
 
@@ -36,22 +34,31 @@ func generateCounter(ctx context.Context, count int) <-chan interface{} {
 }
 
 func main() {
-	ctx, _ := context.WithTimeout(context.Background(), 20*time.Second)
-	t, pctx := tomb.WithContext(ctx)
-	source := tombstreams.NewChanSource(t, generateCounter(pctx, 20))
-	mapper := tombstreams.NewMap(t, Map, 2)
-	sink := tombstreams.NewStdoutSink(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	// The pipeline owns the tomb: build runs on one of its goroutines, so a
+	// blocking To, such as with NewChanSink, needs no wrapping.
+	p := tombstreams.NewPipeline(func(p *tombstreams.Pipeline) error {
+		t := p.Tomb()
+		source := p.Source(tombstreams.NewChanSource(t, generateCounter(p.Context(), 20)))
+		mapper := p.Stage(tombstreams.NewMap(t, Map, 2))
+		sink := tombstreams.NewStdoutSink(t)
 
-	// If using NewChanSink, the pipeline will need to be wrapped in tomb.Go
-	source.Via(mapper).To(sink)
+		source.Via(mapper).To(sink)
+		return nil
+	})
+	p.Run(ctx)
 
-	<-t.Dead()
+	err := p.Wait()
 	fmt.Println("Done")
 
-	err := source.Tomb().Err()
 	if err != nil {
 		fmt.Printf("(Tomb) Exited with error:  %v\n", err)
 	}
+	for _, stats := range p.Summary() {
+		fmt.Printf("%s: %d processed, %d failed, %d dropped\n", stats.Stage, stats.Processed, stats.Failed, stats.Dropped)
+	}
 	// The parent context is not affected by the state of the pipeline.
 	// However, tomb will cancel the child context when the pipeline exits.
 	err = ctx.Err()
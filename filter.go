@@ -19,11 +19,9 @@ type FilterFunc func(interface{}) (bool, error)
 //        |    |                    |
 // out -- 1 -- 2 ------------------ 5 --
 type Filter struct {
+	stage
 	FilterF     FilterFunc
-	in          chan interface{}
-	out         chan interface{}
 	parallelism uint
-	t           *tomb.Tomb
 }
 
 // Verify Filter satisfies the Flow interface.
@@ -31,14 +29,13 @@ var _ Flow = (*Filter)(nil)
 
 // NewFilter returns a new Filter instance.
 // filterFunc is the filter predicate function.
-// parallelism is the flow parallelism factor. In case the events order matters, use parallelism = 1.
-func NewFilter(t *tomb.Tomb, filterFunc FilterFunc, parallelism uint) *Filter {
+// parallelism is the flow parallelism factor. In case the events order matters, use parallelism = 1
+// or the Ordered option.
+func NewFilter(t *tomb.Tomb, filterFunc FilterFunc, parallelism uint, opts ...Option) *Filter {
 	filter := &Filter{
+		newStage(t, newOptions("Filter", opts, errorPolicyFeature, orderedFeature)),
 		filterFunc,
-		make(chan interface{}),
-		make(chan interface{}),
 		parallelism,
-		t,
 	}
 	if t.Alive() {
 		t.Go(filter.doStream)
@@ -46,68 +43,28 @@ func NewFilter(t *tomb.Tomb, filterFunc FilterFunc, parallelism uint) *Filter {
 	return filter
 }
 
-// Via streams data through the given flow
-func (f *Filter) Via(flow Flow) Flow {
-	if f.t.Alive() {
-		f.t.Go(func() error {
-			f.transmit(flow)
-			return nil
-		})
-	}
-	return flow
-}
-
-// To streams data to the given sink
-func (f *Filter) To(sink Sink) {
-	f.transmit(sink)
-}
-
-// Out returns an output channel for sending data
-func (f *Filter) Out() <-chan interface{} {
-	return f.out
-}
-
-// In returns an input channel for receiving data
-func (f *Filter) In() chan<- interface{} {
-	return f.in
-}
-
-func (f *Filter) Tomb() *tomb.Tomb {
-	return f.t
-}
+// throws items that are not satisfying the filter function
+func (f *Filter) doStream() error {
+	defer f.opts.onError.close()
 
-func (f *Filter) transmit(inlet Inlet) {
-	defer close(inlet.In())
-	for {
-		var e interface{}
-		select {
-		case elem, ok := <-f.Out():
-			if ok {
-				e = elem
-			} else {
-				return
+	if f.opts.ordered {
+		return f.doOrdered(f.parallelism, func(elem interface{}) ([]interface{}, error) {
+			include, _, err := apply(f.t, f.Cancelled(), f.opts, elem, f.FilterF)
+			if !include {
+				return nil, err
 			}
-		case <-f.t.Dying():
-			return
-		}
-		select {
-		case inlet.In() <- e:
-		case <-f.t.Dying():
-			return
-		}
+			return []interface{}{elem}, nil
+		})
 	}
-}
 
-// throws items that are not satisfying the filter function
-func (f *Filter) doStream() error {
 	defer close(f.out)
 
 	var wg sync.WaitGroup
-	wg.Add(int(f.parallelism))
 	for i := 0; i < int(f.parallelism); i++ {
 		if !f.t.Alive() {
 			break
 		}
+		wg.Add(1)
 		f.t.Go(func() error {
 			defer wg.Done()
 			for {
@@ -117,7 +74,7 @@ func (f *Filter) doStream() error {
 				select {
 				case elem, ok := <-f.in:
 					if ok {
-						include, err = f.FilterF(elem)
+						include, _, err = apply(f.t, f.Cancelled(), f.opts, elem, f.FilterF)
 						if err != nil {
 							return err
 						}
@@ -129,9 +86,7 @@ func (f *Filter) doStream() error {
 					return nil
 				}
 				if include {
-					select {
-					case f.out <- e:
-					case <-f.t.Dying():
+					if !f.emit(e) {
 						return nil
 					}
 				}
@@ -17,11 +17,9 @@ type FlatMapFunc func(interface{}) ([]interface{}, error)
 //        |    |           |   |    |
 // out -- 1' - 2' -------- 4'- 4''- 5' -
 type FlatMap struct {
+	stage
 	FlatMapF    FlatMapFunc
-	in          chan interface{}
-	out         chan interface{}
 	parallelism uint
-	t           *tomb.Tomb
 }
 
 // Verify FlatMap satisfies the Flow interface.
@@ -29,14 +27,13 @@ var _ Flow = (*FlatMap)(nil)
 
 // NewFlatMap returns a new FlatMap instance.
 // flatMapFunc is the FlatMap transformation function.
-// parallelism is the flow parallelism factor. In case the events order matters, use parallelism = 1.
-func NewFlatMap(t *tomb.Tomb, flatMapFunc FlatMapFunc, parallelism uint) *FlatMap {
+// parallelism is the flow parallelism factor. In case the events order matters, use parallelism = 1
+// or the Ordered option.
+func NewFlatMap(t *tomb.Tomb, flatMapFunc FlatMapFunc, parallelism uint, opts ...Option) *FlatMap {
 	flatMap := &FlatMap{
+		newStage(t, newOptions("FlatMap", opts, errorPolicyFeature, orderedFeature)),
 		flatMapFunc,
-		make(chan interface{}),
-		make(chan interface{}),
 		parallelism,
-		t,
 	}
 	if t.Alive() {
 		t.Go(flatMap.doStream)
@@ -44,67 +41,24 @@ func NewFlatMap(t *tomb.Tomb, flatMapFunc FlatMapFunc, parallelism uint) *FlatMa
 	return flatMap
 }
 
-// Via streams data through the given flow
-func (fm *FlatMap) Via(flow Flow) Flow {
-	if fm.t.Alive() {
-		fm.t.Go(func() error {
-			fm.transmit(flow)
-			return nil
-		})
-	}
-	return flow
-}
-
-// To streams data to the given sink
-func (fm *FlatMap) To(sink Sink) {
-	fm.transmit(sink)
-}
-
-// Out returns an output channel for sending data
-func (fm *FlatMap) Out() <-chan interface{} {
-	return fm.out
-}
-
-// In returns an input channel for receiving data
-func (fm *FlatMap) In() chan<- interface{} {
-	return fm.in
-}
-
-func (fm *FlatMap) Tomb() *tomb.Tomb {
-	return fm.t
-}
+func (fm *FlatMap) doStream() error {
+	defer fm.opts.onError.close()
 
-func (fm *FlatMap) transmit(inlet Inlet) {
-	defer close(inlet.In())
-	for {
-		var e interface{}
-		select {
-		case elem, ok := <-fm.Out():
-			if ok {
-				e = elem
-			} else {
-				return
-			}
-		case <-fm.t.Dying():
-			return
-		}
-		select {
-		case inlet.In() <- e:
-		case <-fm.t.Dying():
-			return
-		}
+	if fm.opts.ordered {
+		return fm.doOrdered(fm.parallelism, func(elem interface{}) ([]interface{}, error) {
+			trans, _, err := apply(fm.t, fm.Cancelled(), fm.opts, elem, fm.FlatMapF)
+			return trans, err
+		})
 	}
-}
 
-func (fm *FlatMap) doStream() error {
 	defer close(fm.out)
 
 	var wg sync.WaitGroup
-	wg.Add(int(fm.parallelism))
 	for i := 0; i < int(fm.parallelism); i++ {
 		if !fm.t.Alive() {
 			break
 		}
+		wg.Add(1)
 		fm.t.Go(func() error {
 			defer wg.Done()
 			for {
@@ -113,7 +67,7 @@ func (fm *FlatMap) doStream() error {
 				select {
 				case elem, ok := <-fm.in:
 					if ok {
-						trans, err = fm.FlatMapF(elem)
+						trans, _, err = apply(fm.t, fm.Cancelled(), fm.opts, elem, fm.FlatMapF)
 						if err != nil {
 							return err
 						}
@@ -124,14 +78,11 @@ func (fm *FlatMap) doStream() error {
 					return nil
 				}
 				for _, item := range trans {
-					select {
-					case fm.out <- item:
-					case <-fm.t.Dying():
+					if !fm.emit(item) {
 						return nil
 					}
 				}
 			}
-			return nil
 		})
 	}
 
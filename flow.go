@@ -1,7 +1,11 @@
 package tombstreams
 
 import (
+	"fmt"
+	"reflect"
 	"sync"
+
+	"gopkg.in/tomb.v2"
 )
 
 // DoStream streams data from the outlet to inlet.
@@ -11,47 +15,113 @@ func DoStream(outlet Outlet, inlet Inlet) {
 		return
 	}
 	t.Go(func() error {
-		defer close(inlet.In())
-		for {
-			var elem interface{}
-			select {
-			case e, ok := <-outlet.Out():
-				if ok {
-					elem = e
-					// inlet.In() <- elem
-				} else {
-					return nil
-				}
-			case <-t.Dying():
-				return nil
-			}
-			select {
-			case inlet.In() <- elem:
-			case <-t.Dying():
-				return nil
+		transmit(outlet, inlet)
+		return nil
+	})
+}
+
+// transmit streams data from the outlet to inlet until the outlet is closed
+// or the tomb is dying, then closes the inlet.
+// If the inlet is cancelled, the outlet is cancelled in turn.
+func transmit(outlet Outlet, inlet Inlet) {
+	t := outlet.Tomb()
+	defer close(inlet.In())
+	cancelled := cancelledOf(inlet)
+	for {
+		var elem interface{}
+		select {
+		case e, ok := <-outlet.Out():
+			if ok {
+				elem = e
+			} else {
+				return
 			}
+		case <-cancelled:
+			cancelUpstream(outlet)
+			return
+		case <-t.Dying():
+			return
 		}
+		select {
+		case inlet.In() <- elem:
+		case <-cancelled:
+			cancelUpstream(outlet)
+			return
+		case <-t.Dying():
+			return
+		}
+	}
+}
 
-		return nil
-	})
+// stage holds the channels, tomb and options of a Flow and implements the
+// plumbing part of the interface. Flows embed it and only provide their doStream.
+type stage struct {
+	*cancellation
+	in   chan interface{}
+	out  chan interface{}
+	t    *tomb.Tomb
+	opts *options
+}
+
+func newStage(t *tomb.Tomb, o *options) stage {
+	return stage{
+		cancellation: newCancellation(),
+		in:           make(chan interface{}, o.inBuffer),
+		out:          make(chan interface{}, o.outBuffer),
+		t:            t,
+		opts:         o,
+	}
+}
+
+// Via streams data through the given flow
+func (s *stage) Via(flow Flow) Flow {
+	DoStream(s, flow)
+	return flow
+}
+
+// To streams data to the given sink
+func (s *stage) To(sink Sink) {
+	transmit(s, sink)
+}
+
+// Out returns an output channel for sending data
+func (s *stage) Out() <-chan interface{} {
+	return s.out
+}
+
+// In returns an input channel for receiving data
+func (s *stage) In() chan<- interface{} {
+	return s.in
+}
+
+// Tomb returns the tomb context
+func (s *stage) Tomb() *tomb.Tomb {
+	return s.t
 }
 
 // FanOut creates a number of identical flows from the single outlet.
 // This can be useful when writing to multiple sinks is required.
-func FanOut(outlet Outlet, magnitude int) []Flow {
+// The options configure the PassThrough of every flow.
+// A cancelled flow stops receiving elements, and the outlet is cancelled once
+// every flow is.
+func FanOut(outlet Outlet, magnitude int, opts ...Option) []Flow {
 	t := outlet.Tomb()
+	o := newOptions("FanOut", opts)
 	out := make([]Flow, magnitude)
 	for i := 0; i < magnitude; i++ {
-		out[i] = NewPassThrough(t)
+		out[i] = NewPassThrough(t, o.passThroughOptions()...)
 	}
 
 	if t.Alive() {
 		t.Go(func() error {
+			done := make(chan struct{})
+			defer close(done)
 			defer func() {
 				for i := 0; i < magnitude; i++ {
 					close(out[i].In())
 				}
 			}()
+			cancelled := allCancelled(t, out, done)
 			for {
 				var e interface{}
 				select {
@@ -61,19 +131,203 @@ func FanOut(outlet Outlet, magnitude int) []Flow {
 					} else {
 						return nil
 					}
+				case <-cancelled:
+					cancelUpstream(outlet)
+					return nil
 				case <-t.Dying():
 					return nil
 				}
 				for _, socket := range out {
 					select {
 					case socket.In() <- e:
+					case <-cancelledOf(socket):
 					case <-t.Dying():
 						return nil
 					}
 				}
 			}
+		})
+	}
 
-			return nil
+	return out
+}
+
+// BalanceStrategy defines how Balance picks the flow of an element.
+type BalanceStrategy int
+
+const (
+	// BalanceRoundRobin sends the elements to the flows in turn.
+	BalanceRoundRobin BalanceStrategy = iota
+	// BalanceFirstReady sends every element to the first flow ready to
+	// receive it, so the least busy flows get the most elements.
+	BalanceFirstReady
+)
+
+// Balance creates a number of flows from the single outlet and sends every
+// element to exactly one of them.
+// This can be useful to scale a chain of stages horizontally.
+// The options configure the PassThrough of every flow.
+// A cancelled flow stops receiving elements, and the outlet is cancelled once
+// every flow is.
+func Balance(outlet Outlet, magnitude int, strategy BalanceStrategy, opts ...Option) []Flow {
+	if magnitude < 1 {
+		panic("No flows to balance")
+	}
+	t := outlet.Tomb()
+	o := newOptions("Balance", opts)
+	out := make([]Flow, magnitude)
+	for i := 0; i < magnitude; i++ {
+		out[i] = NewPassThrough(t, o.passThroughOptions()...)
+	}
+
+	if t.Alive() {
+		t.Go(func() error {
+			done := make(chan struct{})
+			defer close(done)
+			defer func() {
+				for i := 0; i < magnitude; i++ {
+					close(out[i].In())
+				}
+			}()
+			cancelled := allCancelled(t, out, done)
+			// cases holds one send and one cancellation per flow, then the
+			// dying tomb. A case with a zero Chan is ignored by reflect.Select,
+			// which is how cancelled flows are retired.
+			cases := make([]reflect.SelectCase, 2*magnitude+1)
+			for i, socket := range out {
+				cases[i] = reflect.SelectCase{Dir: reflect.SelectSend, Chan: reflect.ValueOf(socket.In())}
+				cases[magnitude+i] = reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(cancelledOf(socket))}
+			}
+			cases[2*magnitude] = reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(t.Dying())}
+			live := magnitude
+			retire := func(i int) {
+				cases[i].Chan = reflect.Value{}
+				cases[magnitude+i].Chan = reflect.Value{}
+				live--
+			}
+			next := 0
+			for {
+				var e interface{}
+				select {
+				case elem, ok := <-outlet.Out():
+					if ok {
+						e = elem
+					} else {
+						return nil
+					}
+				case <-cancelled:
+					cancelUpstream(outlet)
+					return nil
+				case <-t.Dying():
+					return nil
+				}
+				for sent := false; !sent; {
+					if live == 0 {
+						cancelUpstream(outlet)
+						return nil
+					}
+					if strategy == BalanceFirstReady {
+						for i := 0; i < magnitude; i++ {
+							cases[i].Send = reflect.ValueOf(&e).Elem()
+						}
+						chosen, _, _ := reflect.Select(cases)
+						switch {
+						case chosen == 2*magnitude:
+							return nil
+						case chosen >= magnitude:
+							retire(chosen - magnitude)
+						default:
+							sent = true
+						}
+						continue
+					}
+					if cases[next].Chan.IsValid() {
+						select {
+						case out[next].In() <- e:
+							sent = true
+						case <-cancelledOf(out[next]):
+							retire(next)
+						case <-t.Dying():
+							return nil
+						}
+					}
+					next = (next + 1) % magnitude
+				}
+			}
+		})
+	}
+
+	return out
+}
+
+// PartitionFunc returns the index of the flow an element is routed to.
+type PartitionFunc func(interface{}) (int, error)
+
+// Partition creates a number of flows from the single outlet and routes every
+// element to the one chosen by partitionFunc.
+// An error from partitionFunc, or an index out of [0, magnitude), is handled
+// by the error policy set in the options, which also configure the
+// PassThrough of every flow.
+// The elements routed to a cancelled flow are discarded, and the outlet is
+// cancelled once every flow is.
+func Partition(outlet Outlet, magnitude int, partitionFunc PartitionFunc, opts ...Option) []Flow {
+	if magnitude < 1 {
+		panic("No flows to partition")
+	}
+	t := outlet.Tomb()
+	o := newOptions("Partition", opts, errorPolicyFeature)
+	out := make([]Flow, magnitude)
+	for i := 0; i < magnitude; i++ {
+		out[i] = NewPassThrough(t, o.passThroughOptions()...)
+	}
+	route := func(elem interface{}) (int, error) {
+		i, err := partitionFunc(elem)
+		if err == nil && (i < 0 || i >= magnitude) {
+			err = fmt.Errorf("partition index %d out of range [0, %d)", i, magnitude)
+		}
+		return i, err
+	}
+
+	if t.Alive() {
+		t.Go(func() error {
+			done := make(chan struct{})
+			defer close(done)
+			defer o.onError.close()
+			defer func() {
+				for i := 0; i < magnitude; i++ {
+					close(out[i].In())
+				}
+			}()
+			cancelled := allCancelled(t, out, done)
+			for {
+				var e interface{}
+				select {
+				case elem, ok := <-outlet.Out():
+					if ok {
+						e = elem
+					} else {
+						return nil
+					}
+				case <-cancelled:
+					cancelUpstream(outlet)
+					return nil
+				case <-t.Dying():
+					return nil
+				}
+				i, keep, err := apply(t, cancelled, o, e, route)
+				if err != nil {
+					return err
+				}
+				if !keep {
+					continue
+				}
+				select {
+				case out[i].In() <- e:
+				case <-cancelledOf(out[i]):
+				case <-t.Dying():
+					return nil
+				}
+			}
 		})
 	}
 
@@ -81,6 +335,9 @@ func FanOut(outlet Outlet, magnitude int) []Flow {
 }
 
 // Merge merges multiple flows into a single flow.
+// Cancelling the merged flow cancels every merged one.
+// It takes no options, its arguments being variadic: send the result through
+// a PassThrough to buffer it.
 func Merge(outlets ...Flow) Flow {
 	if len(outlets) < 1 {
 		panic("No flows to merge")
@@ -107,17 +364,21 @@ func Merge(outlets ...Flow) Flow {
 							} else {
 								return nil
 							}
+						case <-merged.Cancelled():
+							cancelUpstream(outlet)
+							return nil
 						case <-t.Dying():
 							return nil
 						}
 						select {
 						case merged.In() <- e:
+						case <-merged.Cancelled():
+							cancelUpstream(outlet)
+							return nil
 						case <-t.Dying():
 							return nil
 						}
 					}
-
-					return nil
 				}
 			}(out))
 		}
@@ -136,3 +397,171 @@ func Merge(outlets ...Flow) Flow {
 
 	return merged
 }
+
+// Concat streams the outlets one after the other: it emits every element of
+// the first outlet until it completes, then every element of the second
+// outlet, and so on. The following outlets are not read in the meantime.
+// This can be useful to replay a backlog before switching to a live source.
+// Cancelling the concatenated flow cancels the outlets not completed yet.
+// It takes no options, its arguments being variadic: send the result through
+// a PassThrough to buffer it.
+//
+// a   -- a1 -- a2 --|
+// b   -- b1 ---------------- b2 --
+//        |     |      |      |
+// out -- a1 -- a2 --- b1 --- b2 --
+func Concat(outlets ...Outlet) Flow {
+	if len(outlets) < 1 {
+		panic("No outlets to concat")
+	}
+
+	t := outlets[0].Tomb()
+	concatenated := NewPassThrough(t)
+	if t.Alive() {
+		t.Go(func() error {
+			defer close(concatenated.In())
+			for i, outlet := range outlets {
+				if !forward(t, outlet, concatenated) {
+					for _, rest := range outlets[i:] {
+						cancelUpstream(rest)
+					}
+					return nil
+				}
+			}
+			return nil
+		})
+	}
+
+	return concatenated
+}
+
+// ZipFunc combines one element of every zipped outlet into a single element.
+type ZipFunc func([]interface{}) (interface{}, error)
+
+// Zip combines the elements of the outlets pairwise: it emits an []interface{}
+// holding the next element of every outlet, in the order of the outlets.
+// The zipped flow completes as soon as any outlet completes, or is cancelled;
+// the other outlets are then cancelled, or drained if they are not Cancellable.
+// It takes no options, its arguments being variadic: send the result through
+// a PassThrough to buffer it.
+//
+// a   -- a1 ---- a2 ---- a3 --|
+// b   ---- b1 ---- b2 ---------- b3 --
+//          |       |             |
+// out ---- [a1 b1] [a2 b2] ----- [a3 b3]
+func Zip(outlets ...Outlet) Flow {
+	return ZipWith(func(elems []interface{}) (interface{}, error) {
+		return elems, nil
+	}, outlets...)
+}
+
+// ZipWith combines the elements of the outlets pairwise with zipFunc.
+// An error from zipFunc kills the tomb. See Zip for the completion rules and
+// options.
+func ZipWith(zipFunc ZipFunc, outlets ...Outlet) Flow {
+	if len(outlets) < 1 {
+		panic("No outlets to zip")
+	}
+
+	t := outlets[0].Tomb()
+	zipped := NewPassThrough(t)
+	if t.Alive() {
+		t.Go(func() error {
+			err := zip(t, zipFunc, outlets, zipped)
+			close(zipped.In())
+			if err != nil {
+				return err
+			}
+			// Stop the other outlets, so their upstream is not blocked forever.
+			for _, outlet := range outlets {
+				discard(t, outlet)
+			}
+			return nil
+		})
+	}
+
+	return zipped
+}
+
+func zip(t *tomb.Tomb, zipFunc ZipFunc, outlets []Outlet, inlet Inlet) error {
+	cancelled := cancelledOf(inlet)
+	for {
+		elems := make([]interface{}, len(outlets))
+		for i, outlet := range outlets {
+			select {
+			case elem, ok := <-outlet.Out():
+				if !ok {
+					return nil
+				}
+				elems[i] = elem
+			case <-cancelled:
+				return nil
+			case <-t.Dying():
+				return nil
+			}
+		}
+		e, err := zipFunc(elems)
+		if err != nil {
+			return err
+		}
+		select {
+		case inlet.In() <- e:
+		case <-cancelled:
+			return nil
+		case <-t.Dying():
+			return nil
+		}
+	}
+}
+
+// forward sends the elements of the outlet to inlet until the outlet is closed.
+// Unlike transmit it leaves the inlet open. It returns false if the inlet was
+// cancelled or the tomb started dying first.
+func forward(t *tomb.Tomb, outlet Outlet, inlet Inlet) bool {
+	cancelled := cancelledOf(inlet)
+	for {
+		var e interface{}
+		select {
+		case elem, ok := <-outlet.Out():
+			if !ok {
+				return true
+			}
+			e = elem
+		case <-cancelled:
+			return false
+		case <-t.Dying():
+			return false
+		}
+		select {
+		case inlet.In() <- e:
+		case <-cancelled:
+			return false
+		case <-t.Dying():
+			return false
+		}
+	}
+}
+
+// discard stops an outlet whose elements are no longer needed: it cancels
+// the outlet, or drains it if it is not Cancellable.
+func discard(t *tomb.Tomb, outlet Outlet) {
+	if _, ok := outlet.(Cancellable); ok {
+		cancelUpstream(outlet)
+		return
+	}
+	drain(t, outlet)
+}
+
+// drain discards the elements of the outlet until it is closed or the tomb is dying.
+func drain(t *tomb.Tomb, outlet Outlet) {
+	for {
+		select {
+		case _, ok := <-outlet.Out():
+			if !ok {
+				return
+			}
+		case <-t.Dying():
+			return
+		}
+	}
+}
@@ -1,9 +1,15 @@
 module github.com/artificial-james/tombstreams
 
-go 1.16
+go 1.18
 
 require (
 	github.com/stretchr/testify v1.7.0
-	golang.org/x/net v0.0.0-20211011170408-caeb26a5c8c0 // indirect
 	gopkg.in/tomb.v2 v2.0.0-20161208151619-d5d1b5820637
 )
+
+require (
+	github.com/davecgh/go-spew v1.1.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/net v0.0.0-20211011170408-caeb26a5c8c0 // indirect
+	gopkg.in/yaml.v3 v3.0.0-20200313102051-9f266ea9e77c // indirect
+)
@@ -0,0 +1,210 @@
+package tombstreams
+
+import (
+	"sync"
+	"time"
+
+	"gopkg.in/tomb.v2"
+)
+
+// KeyFunc extracts the key of an element. Keys must be comparable.
+type KeyFunc func(interface{}) (interface{}, error)
+
+// SubFlowFactory builds the processing chain of the sub-stream of a key.
+// sub streams the elements of the key; the factory connects its stages with
+// sub.Via on the tomb of the GroupBy and returns the last one.
+type SubFlowFactory func(key interface{}, sub Flow) Flow
+
+// GroupBy splits the stream by key into independent sub-streams, each
+// processed by its own chain of stages, and merges their outputs.
+// Elements with the same key are processed in order, while different keys
+// are processed concurrently.
+//
+// in  -- a1 -- b1 -- a2 -- c1 -- b2 --
+//        |     |     |     |     |
+//    [ a: -- a1 ------ a2 ------------ ]
+//    [ b: -------- b1 ------------ b2 -]
+//    [ c: -------------------- c1 ---- ]
+//        |     |     |     |     |
+// out -- a1'-- b1'-- a2'-- c1'-- b2'--
+type GroupBy struct {
+	stage
+	KeyF    KeyFunc
+	factory SubFlowFactory
+	maxKeys int
+	idle    time.Duration
+}
+
+// Verify GroupBy satisfies the Flow interface.
+var _ Flow = (*GroupBy)(nil)
+
+// NewGroupBy returns a new GroupBy instance.
+// keyFunc extracts the key of every element, its errors are handled by the
+// error policy of the stage.
+// factory builds the chain of a key when its first element arrives.
+// maxKeys limits the number of active sub-streams, 0 means unlimited. Once the
+// limit is reached the least recently used sub-stream is closed to make room.
+// idle closes a sub-stream that has not received an element for that long,
+// 0 disables it. A closed sub-stream finishes processing its elements, and a
+// new one is built for its key once they have all been emitted.
+// Once the chain of a key is cancelled, such as by a Take, the elements of
+// the key are dropped, see Dropped.
+func NewGroupBy(t *tomb.Tomb, keyFunc KeyFunc, factory SubFlowFactory, maxKeys int, idle time.Duration, opts ...Option) *GroupBy {
+	if maxKeys < 0 {
+		panic("GroupBy maxKeys must not be negative")
+	}
+	if idle < 0 {
+		panic("GroupBy idle must not be negative")
+	}
+	groupBy := &GroupBy{
+		newStage(t, newOptions("GroupBy", opts, errorPolicyFeature)),
+		keyFunc,
+		factory,
+		maxKeys,
+		idle,
+	}
+	if t.Alive() {
+		t.Go(groupBy.doStream)
+	}
+	return groupBy
+}
+
+type subStream struct {
+	in   *PassThrough
+	last time.Time
+	// done is closed once every element of the sub-stream has been emitted.
+	done chan struct{}
+}
+
+func (gb *GroupBy) doStream() error {
+	var wg sync.WaitGroup
+	defer close(gb.out)
+	defer wg.Wait()
+	defer gb.opts.onError.close()
+
+	active := make(map[interface{}]*subStream)
+	draining := make(map[interface{}]chan struct{})
+	defer func() {
+		for _, sub := range active {
+			close(sub.in.In())
+		}
+	}()
+
+	evict := func(key interface{}) {
+		sub := active[key]
+		close(sub.in.In())
+		delete(active, key)
+		draining[key] = sub.done
+	}
+
+	open := func(key interface{}) (*subStream, bool) {
+		// Wait for the previous sub-stream of the key, to keep its elements in order.
+		if done, ok := draining[key]; ok {
+			select {
+			case <-done:
+				delete(draining, key)
+			case <-gb.Cancelled():
+				return nil, false
+			case <-gb.t.Dying():
+				return nil, false
+			}
+		}
+		if gb.maxKeys > 0 && len(active) >= gb.maxKeys {
+			var lru interface{}
+			var oldest time.Time
+			for k, sub := range active {
+				if oldest.IsZero() || sub.last.Before(oldest) {
+					lru, oldest = k, sub.last
+				}
+			}
+			evict(lru)
+		}
+
+		sub := &subStream{
+			in:   NewPassThrough(gb.t),
+			done: make(chan struct{}),
+		}
+		outlet := gb.factory(key, sub.in)
+		active[key] = sub
+		wg.Add(1)
+		gb.t.Go(func() error {
+			defer wg.Done()
+			defer close(sub.done)
+			for {
+				select {
+				case elem, ok := <-outlet.Out():
+					if !ok {
+						return nil
+					}
+					if !gb.emit(elem) {
+						// Stop the chain of the key as well.
+						cancelUpstream(outlet)
+						return nil
+					}
+				case <-gb.t.Dying():
+					return nil
+				}
+			}
+		})
+		return sub, true
+	}
+
+	var sweep <-chan time.Time
+	if gb.idle > 0 {
+		interval := gb.idle / 2
+		if interval <= 0 {
+			interval = gb.idle
+		}
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		sweep = ticker.C
+	}
+
+	for {
+		select {
+		case elem, ok := <-gb.in:
+			if !ok {
+				return nil
+			}
+			key, keep, err := apply(gb.t, gb.Cancelled(), gb.opts, elem, gb.KeyF)
+			if err != nil {
+				return err
+			}
+			if !keep {
+				continue
+			}
+			sub, ok := active[key]
+			if !ok {
+				if sub, ok = open(key); !ok {
+					return nil
+				}
+			}
+			sub.last = time.Now()
+			select {
+			case sub.in.In() <- elem:
+			case <-sub.in.Cancelled():
+				// The chain of the key needs no more elements, such as after a Take.
+				gb.drop()
+			case <-gb.Cancelled():
+				return nil
+			case <-gb.t.Dying():
+				return nil
+			}
+		case now := <-sweep:
+			for key, sub := range active {
+				if now.Sub(sub.last) >= gb.idle {
+					evict(key)
+				}
+			}
+			for key, done := range draining {
+				select {
+				case <-done:
+					delete(draining, key)
+				default:
+				}
+			}
+		case <-gb.t.Dying():
+			return nil
+		}
+	}
+}
@@ -0,0 +1,244 @@
+package tombstreams
+
+import (
+	"time"
+
+	"gopkg.in/tomb.v2"
+)
+
+// JoinKind defines which elements a Join emits.
+type JoinKind int
+
+const (
+	// JoinInner emits only the pairs of matching elements.
+	JoinInner JoinKind = iota
+	// JoinLeftOuter also emits every left element that found no match within
+	// the window, paired with a nil Right, once it expires.
+	JoinLeftOuter
+	// JoinFullOuter also emits the unmatched elements of both sides, paired
+	// with a nil Right or a nil Left, once they expire.
+	JoinFullOuter
+)
+
+// Pair is the element emitted by Join.
+type Pair struct {
+	Left  interface{}
+	Right interface{}
+}
+
+// Join matches the elements of two outlets by key: it emits a Pair for every
+// left and right element with equal keys that arrived within window of each
+// other. An element is kept for the duration of the window, so it is paired
+// with every match it meets meanwhile, and is then evicted.
+// The joined flow completes once both outlets have completed. Cancelling it
+// cancels both outlets.
+//
+// left  -- a1 ---- b1 -------------------------------------
+// right ------ a2 -------- c2 ------------------------------
+//              |       [---- window ----]
+//              |           [---- window ----]
+//              |                        |   |
+// out   ------ {a1 a2} ---------------- {b1 nil} {nil c2} --
+//                                       JoinFullOuter only
+//
+// The options configure the error policy of the key functions and the output
+// of the joined flow.
+func Join(left, right Outlet, leftKey, rightKey KeyFunc, window time.Duration, kind JoinKind, opts ...Option) Flow {
+	if window <= 0 {
+		panic("Join window must be positive")
+	}
+	t := left.Tomb()
+	o := newOptions("Join", opts, errorPolicyFeature)
+	joined := NewPassThrough(t, o.passThroughOptions()...)
+
+	if t.Alive() {
+		t.Go(func() error {
+			defer o.onError.close()
+			defer close(joined.In())
+			j := &join{
+				t:         t,
+				o:         o,
+				inlet:     joined,
+				cancelled: joined.Cancelled(),
+				window:    window,
+				left:      newJoinSide(leftKey, kind != JoinInner),
+				right:     newJoinSide(rightKey, kind == JoinFullOuter),
+			}
+			if err := j.run(left.Out(), right.Out()); err != nil {
+				return err
+			}
+			// Stop the sides that are still open, they are no longer read.
+			discard(t, left)
+			discard(t, right)
+			return nil
+		})
+	}
+
+	return joined
+}
+
+type joinEntry struct {
+	elem    interface{}
+	key     interface{}
+	at      time.Time
+	matched bool
+}
+
+// joinSide holds the elements of one side of a Join that are still within the window.
+type joinSide struct {
+	keyF KeyFunc
+	// outer is set when the unmatched elements of the side are emitted.
+	outer bool
+	// entries are in arrival order, so the expired ones are at the front.
+	entries []*joinEntry
+	byKey   map[interface{}][]*joinEntry
+}
+
+func newJoinSide(keyF KeyFunc, outer bool) *joinSide {
+	return &joinSide{
+		keyF:  keyF,
+		outer: outer,
+		byKey: make(map[interface{}][]*joinEntry),
+	}
+}
+
+// expire removes the entries that arrived at or before deadline and returns
+// the ones that were never matched.
+func (js *joinSide) expire(deadline time.Time) []*joinEntry {
+	var unmatched []*joinEntry
+	i := 0
+	for i < len(js.entries) && !js.entries[i].at.After(deadline) {
+		entry := js.entries[i]
+		if !entry.matched {
+			unmatched = append(unmatched, entry)
+		}
+		// Entries of a key are in arrival order as well.
+		if rest := js.byKey[entry.key][1:]; len(rest) > 0 {
+			js.byKey[entry.key] = rest
+		} else {
+			delete(js.byKey, entry.key)
+		}
+		i++
+	}
+	js.entries = js.entries[i:]
+	return unmatched
+}
+
+type join struct {
+	t         *tomb.Tomb
+	o         *options
+	inlet     Inlet
+	cancelled <-chan struct{}
+	window    time.Duration
+	left      *joinSide
+	right     *joinSide
+}
+
+func (j *join) run(leftOut, rightOut <-chan interface{}) error {
+	ticker := time.NewTicker(j.window / 2)
+	defer ticker.Stop()
+
+	for leftOut != nil || rightOut != nil {
+		select {
+		case elem, ok := <-leftOut:
+			if !ok {
+				leftOut = nil
+				continue
+			}
+			if err := j.receive(elem, j.left, j.right, true); err != nil || j.stopped() {
+				return err
+			}
+		case elem, ok := <-rightOut:
+			if !ok {
+				rightOut = nil
+				continue
+			}
+			if err := j.receive(elem, j.right, j.left, false); err != nil || j.stopped() {
+				return err
+			}
+		case now := <-ticker.C:
+			if !j.expire(now.Add(-j.window)) {
+				return nil
+			}
+		case <-j.cancelled:
+			return nil
+		case <-j.t.Dying():
+			return nil
+		}
+	}
+
+	// Nothing can match anymore, flush the unmatched elements.
+	j.expire(time.Now())
+	return nil
+}
+
+// receive stores elem on its side and pairs it with the matching elements of
+// the other side. fromLeft tells on which side of the Pair elem goes.
+func (j *join) receive(elem interface{}, side, other *joinSide, fromLeft bool) error {
+	key, keep, err := apply(j.t, j.cancelled, j.o, elem, side.keyF)
+	if err != nil || !keep {
+		return err
+	}
+
+	now := time.Now()
+	entry := &joinEntry{elem, key, now, false}
+	for _, match := range other.byKey[key] {
+		// The ticker evicts lazily, so skip what already fell out of the window.
+		if now.Sub(match.at) > j.window {
+			continue
+		}
+		entry.matched = true
+		match.matched = true
+		pair := Pair{match.elem, elem}
+		if fromLeft {
+			pair = Pair{elem, match.elem}
+		}
+		if !j.send(pair) {
+			return nil
+		}
+	}
+	side.entries = append(side.entries, entry)
+	side.byKey[key] = append(side.byKey[key], entry)
+	return nil
+}
+
+// expire evicts the elements that arrived at or before deadline and emits
+// the unmatched ones of the outer sides.
+// It returns false if the join must stop.
+func (j *join) expire(deadline time.Time) bool {
+	for _, entry := range j.left.expire(deadline) {
+		if j.left.outer && !j.send(Pair{entry.elem, nil}) {
+			return false
+		}
+	}
+	for _, entry := range j.right.expire(deadline) {
+		if j.right.outer && !j.send(Pair{nil, entry.elem}) {
+			return false
+		}
+	}
+	return true
+}
+
+// send emits the pair. It returns false if the join must stop.
+func (j *join) send(pair Pair) bool {
+	select {
+	case j.inlet.In() <- pair:
+		return true
+	case <-j.cancelled:
+		return false
+	case <-j.t.Dying():
+		return false
+	}
+}
+
+// stopped reports whether the join was cancelled or the tomb is dying.
+func (j *join) stopped() bool {
+	select {
+	case <-j.cancelled:
+		return true
+	case <-j.t.Dying():
+		return true
+	default:
+		return false
+	}
+}
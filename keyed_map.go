@@ -0,0 +1,122 @@
+package tombstreams
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sync"
+
+	"gopkg.in/tomb.v2"
+)
+
+// KeyedMap is a Map whose parallelism is sharded by key.
+// Every element with the same key is processed by the same worker, so the
+// elements of a key keep their order, while different keys are processed
+// concurrently.
+//
+// in  -- a1 -- b1 -- a2 -- c1 -- b2 --
+//        |     |     |     |     |
+//    [ worker 0: a1 -- a2 -- c1 ------ ]
+//    [ worker 1: ----- b1 -------- b2 -]
+//        |     |     |     |     |
+// out -- a1'-- b1'-- a2'-- c1'-- b2'--
+type KeyedMap struct {
+	stage
+	KeyF        KeyFunc
+	MapF        MapFunc
+	parallelism uint
+}
+
+// Verify KeyedMap satisfies the Flow interface.
+var _ Flow = (*KeyedMap)(nil)
+
+// NewKeyedMap returns a new KeyedMap instance.
+// keyFunc extracts the key of every element, mapFunc is the Map
+// transformation function. Errors of both are handled by the error policy of
+// the stage.
+// parallelism is the number of workers the keys are sharded across.
+func NewKeyedMap(t *tomb.Tomb, keyFunc KeyFunc, mapFunc MapFunc, parallelism uint, opts ...Option) *KeyedMap {
+	if parallelism == 0 {
+		parallelism = 1
+	}
+	keyedMap := &KeyedMap{
+		newStage(t, newOptions("KeyedMap", opts, errorPolicyFeature)),
+		keyFunc,
+		mapFunc,
+		parallelism,
+	}
+	if t.Alive() {
+		t.Go(keyedMap.doStream)
+	}
+	return keyedMap
+}
+
+func (km *KeyedMap) doStream() error {
+	var wg sync.WaitGroup
+	defer close(km.out)
+	defer wg.Wait()
+	defer km.opts.onError.close()
+
+	shards := make([]chan interface{}, km.parallelism)
+	defer func() {
+		for _, shard := range shards {
+			close(shard)
+		}
+	}()
+	for i := range shards {
+		shard := make(chan interface{}, km.opts.inBuffer)
+		shards[i] = shard
+		wg.Add(1)
+		km.t.Go(func() error {
+			defer wg.Done()
+			for elem := range shard {
+				trans, emit, err := apply(km.t, km.Cancelled(), km.opts, elem, km.MapF)
+				if err != nil {
+					return err
+				}
+				if emit && !km.emit(trans) {
+					return nil
+				}
+			}
+			return nil
+		})
+	}
+
+	for {
+		select {
+		case elem, ok := <-km.in:
+			if !ok {
+				return nil
+			}
+			key, keep, err := apply(km.t, km.Cancelled(), km.opts, elem, km.KeyF)
+			if err != nil {
+				return err
+			}
+			if !keep {
+				continue
+			}
+			select {
+			case shards[hashKey(key)%uint64(km.parallelism)] <- elem:
+			case <-km.Cancelled():
+				return nil
+			case <-km.t.Dying():
+				return nil
+			}
+		case <-km.t.Dying():
+			return nil
+		}
+	}
+}
+
+// hashKey hashes a key for sharding.
+func hashKey(key interface{}) uint64 {
+	h := fnv.New64a()
+	switch k := key.(type) {
+	case string:
+		h.Write([]byte(k))
+	case []byte:
+		h.Write(k)
+	default:
+		fmt.Fprint(h, k)
+	}
+	return h.Sum64()
+}
@@ -17,11 +17,9 @@ type MapFunc func(interface{}) (interface{}, error)
 //        |    |      |    |        |
 // out -- 1' - 2' --- 3' - 4' ----- 5' -
 type Map struct {
+	stage
 	MapF        MapFunc
-	in          chan interface{}
-	out         chan interface{}
 	parallelism uint
-	t           *tomb.Tomb
 }
 
 // Verify Map satisfies the Flow interface.
@@ -29,14 +27,13 @@ var _ Flow = (*Map)(nil)
 
 // NewMap returns a new Map instance.
 // mapFunc is the Map transformation function.
-// parallelism is the flow parallelism factor. In case the events order matters, use parallelism = 1.
-func NewMap(t *tomb.Tomb, mapFunc MapFunc, parallelism uint) *Map {
+// parallelism is the flow parallelism factor. In case the events order matters, use parallelism = 1
+// or the Ordered option.
+func NewMap(t *tomb.Tomb, mapFunc MapFunc, parallelism uint, opts ...Option) *Map {
 	_map := &Map{
+		newStage(t, newOptions("Map", opts, errorPolicyFeature, orderedFeature)),
 		mapFunc,
-		make(chan interface{}),
-		make(chan interface{}),
 		parallelism,
-		t,
 	}
 	if t.Alive() {
 		t.Go(_map.doStream)
@@ -44,67 +41,27 @@ func NewMap(t *tomb.Tomb, mapFunc MapFunc, parallelism uint) *Map {
 	return _map
 }
 
-// Via streams data through the given flow
-func (m *Map) Via(flow Flow) Flow {
-	if m.t.Alive() {
-		m.t.Go(func() error {
-			m.transmit(flow)
-			return nil
-		})
-	}
-	return flow
-}
-
-// To streams data to the given sink
-func (m *Map) To(sink Sink) {
-	m.transmit(sink)
-}
-
-// Out returns an output channel for sending data
-func (m *Map) Out() <-chan interface{} {
-	return m.out
-}
-
-// In returns an input channel for receiving data
-func (m *Map) In() chan<- interface{} {
-	return m.in
-}
-
-func (m *Map) Tomb() *tomb.Tomb {
-	return m.t
-}
+func (m *Map) doStream() error {
+	defer m.opts.onError.close()
 
-func (m *Map) transmit(inlet Inlet) {
-	defer close(inlet.In())
-	for {
-		var e interface{}
-		select {
-		case elem, ok := <-m.Out():
-			if ok {
-				e = elem
-			} else {
-				return
+	if m.opts.ordered {
+		return m.doOrdered(m.parallelism, func(elem interface{}) ([]interface{}, error) {
+			trans, emit, err := apply(m.t, m.Cancelled(), m.opts, elem, m.MapF)
+			if !emit {
+				return nil, err
 			}
-		case <-m.t.Dying():
-			return
-		}
-		select {
-		case inlet.In() <- e:
-		case <-m.t.Dying():
-			return
-		}
+			return []interface{}{trans}, nil
+		})
 	}
-}
 
-func (m *Map) doStream() error {
 	defer close(m.out)
 
 	var wg sync.WaitGroup
-	wg.Add(int(m.parallelism))
 	for i := 0; i < int(m.parallelism); i++ {
 		if !m.t.Alive() {
 			break
 		}
+		wg.Add(1)
 		m.t.Go(func() error {
 			defer wg.Done()
 			for {
@@ -113,23 +70,24 @@ func (m *Map) doStream() error {
 				select {
 				case elem, ok := <-m.in:
 					if ok {
-						trans, err = m.MapF(elem)
+						var emit bool
+						trans, emit, err = apply(m.t, m.Cancelled(), m.opts, elem, m.MapF)
 						if err != nil {
 							return err
 						}
+						if !emit {
+							continue
+						}
 					} else {
 						return nil
 					}
 				case <-m.t.Dying():
 					return nil
 				}
-				select {
-				case m.out <- trans:
-				case <-m.t.Dying():
+				if !m.emit(trans) {
 					return nil
 				}
 			}
-			return nil
 		})
 	}
 
@@ -0,0 +1,113 @@
+package tombstreams
+
+// Option configures a stage.
+type Option func(*options)
+
+type options struct {
+	counters  *counters
+	name      string
+	inBuffer  int
+	outBuffer int
+	overflow  OverflowStrategy
+	ordered   bool
+	onError   errorPolicy
+	// features are the options set that only some stages support.
+	features feature
+}
+
+// feature is an option that only some stages support, see newOptions.
+type feature uint
+
+const (
+	// errorPolicyFeature is set by the OnError options, supported by the
+	// stages calling a function on every element.
+	errorPolicyFeature feature = 1 << iota
+	// orderedFeature is set by Ordered, supported by the parallel stages.
+	orderedFeature
+)
+
+// newOptions applies opts to the options of the stage called name.
+// It panics if opts set a feature that is not among supported, since the
+// option would silently have no effect.
+func newOptions(name string, opts []Option, supported ...feature) *options {
+	o := &options{counters: &counters{}, name: name}
+	for _, opt := range opts {
+		opt(o)
+	}
+	unsupported := o.features
+	for _, f := range supported {
+		unsupported &^= f
+	}
+	if unsupported&errorPolicyFeature != 0 {
+		panic(name + " does not support the OnError options")
+	}
+	if unsupported&orderedFeature != 0 {
+		panic(name + " does not support the Ordered option")
+	}
+	return o
+}
+
+// passThroughOptions returns the options of o that configure the PassThrough
+// flows of a junction.
+func (o *options) passThroughOptions() []Option {
+	return []Option{
+		WithName(o.name),
+		WithInputBuffer(o.inBuffer),
+		WithOutputBuffer(o.outBuffer),
+		WithOverflow(o.overflow),
+	}
+}
+
+// WithName names the stage, for example in the DeadLetter of a failed element.
+// Stages are named after their type by default.
+func WithName(name string) Option {
+	return func(o *options) {
+		o.name = name
+	}
+}
+
+// WithInputBuffer sets the capacity of the input channel of the stage.
+// Buffers absorb bursts, so a slow element does not stall upstream at once.
+// Stages are unbuffered by default.
+func WithInputBuffer(capacity int) Option {
+	if capacity < 0 {
+		panic("WithInputBuffer capacity must not be negative")
+	}
+	return func(o *options) {
+		o.inBuffer = capacity
+	}
+}
+
+// WithOutputBuffer sets the capacity of the output channel of the stage.
+// Stages are unbuffered by default. Sinks have no output, so it has no effect on them.
+func WithOutputBuffer(capacity int) Option {
+	if capacity < 0 {
+		panic("WithOutputBuffer capacity must not be negative")
+	}
+	return func(o *options) {
+		o.outBuffer = capacity
+	}
+}
+
+// WithBuffer sets the capacity of both the input and the output channel of the stage.
+func WithBuffer(capacity int) Option {
+	if capacity < 0 {
+		panic("WithBuffer capacity must not be negative")
+	}
+	return func(o *options) {
+		o.inBuffer = capacity
+		o.outBuffer = capacity
+	}
+}
+
+// Ordered makes a parallel stage emit its results in input order:
+// Map, FlatMap and Filter. Other stages panic if it is set.
+// Elements are still processed by parallelism workers, but results are held
+// in a reorder buffer of parallelism slots until every earlier result has
+// been emitted.
+func Ordered() Option {
+	return func(o *options) {
+		o.ordered = true
+		o.features |= orderedFeature
+	}
+}
@@ -0,0 +1,96 @@
+package tombstreams
+
+import (
+	"sync"
+)
+
+type orderedJob struct {
+	elem   interface{}
+	result chan []interface{}
+}
+
+// doOrdered processes the input elements with parallelism workers and emits
+// the results in input order.
+//
+// The dispatcher queues a result slot for every element it hands to a worker,
+// and the emitter drains those slots in the same order. The slot queue is the
+// reorder buffer; it is bounded by parallelism, so a slow element holds back
+// at most parallelism - 1 finished ones.
+func (s *stage) doOrdered(parallelism uint, process func(interface{}) ([]interface{}, error)) error {
+	t := s.t
+	defer close(s.out)
+
+	if parallelism == 0 {
+		parallelism = 1
+	}
+	jobs := make(chan orderedJob)
+	pending := make(chan chan []interface{}, parallelism)
+
+	// doOrdered runs on a tracked goroutine, so t.Go cannot panic here.
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for i := 0; i < int(parallelism); i++ {
+		wg.Add(1)
+		t.Go(func() error {
+			defer wg.Done()
+			for job := range jobs {
+				elems, err := process(job.elem)
+				if err != nil {
+					return err
+				}
+				// result has room for exactly one value, so this never blocks.
+				job.result <- elems
+			}
+			return nil
+		})
+	}
+
+	t.Go(func() error {
+		defer close(jobs)
+		defer close(pending)
+		for {
+			var job orderedJob
+			select {
+			case elem, ok := <-s.in:
+				if !ok {
+					return nil
+				}
+				job = orderedJob{elem, make(chan []interface{}, 1)}
+			case <-t.Dying():
+				return nil
+			}
+			select {
+			case pending <- job.result:
+			case <-s.Cancelled():
+				return nil
+			case <-t.Dying():
+				return nil
+			}
+			select {
+			case jobs <- job:
+			case <-s.Cancelled():
+				return nil
+			case <-t.Dying():
+				return nil
+			}
+		}
+	})
+
+	for result := range pending {
+		var elems []interface{}
+		select {
+		case elems = <-result:
+		case <-s.Cancelled():
+			// The dispatcher may have queued the slot without handing out its job.
+			return nil
+		case <-t.Dying():
+			return nil
+		}
+		for _, elem := range elems {
+			if !s.emit(elem) {
+				return nil
+			}
+		}
+	}
+	return nil
+}
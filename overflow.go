@@ -0,0 +1,119 @@
+package tombstreams
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// OverflowStrategy defines what a stage does with an element when its output
+// buffer is full.
+type OverflowStrategy int
+
+const (
+	// OverflowBlock waits until there is room in the buffer, which blocks
+	// upstream. This is the default.
+	OverflowBlock OverflowStrategy = iota
+	// OverflowDropNewest discards the element that did not fit.
+	OverflowDropNewest
+	// OverflowDropOldest discards the oldest buffered element to make room.
+	// An unbuffered output has no oldest element, so it drops the newest one instead.
+	OverflowDropOldest
+	// OverflowFail kills the tomb with an *OverflowError.
+	OverflowFail
+)
+
+// OverflowError is the reason of death of a tomb killed by a stage using the
+// OverflowFail strategy.
+type OverflowError struct {
+	// Stage is the name of the stage, see WithName.
+	Stage string
+	// Capacity is the capacity of the output buffer that overflowed.
+	Capacity int
+}
+
+func (e *OverflowError) Error() string {
+	return fmt.Sprintf("%s: output buffer overflow (capacity %d)", e.Stage, e.Capacity)
+}
+
+// WithOverflow sets the strategy applied when the output buffer of the stage
+// is full. It is meant for stages with a buffer, see WithOutputBuffer: an
+// unbuffered output is full whenever downstream is not ready to receive.
+// Dropped elements are counted, see Dropped.
+func WithOverflow(strategy OverflowStrategy) Option {
+	return func(o *options) {
+		o.overflow = strategy
+	}
+}
+
+// Dropped returns the number of elements the stage discarded.
+func (s *stage) Dropped() uint64 {
+	return atomic.LoadUint64(&s.opts.counters.dropped)
+}
+
+func (s *stage) drop() {
+	atomic.AddUint64(&s.opts.counters.dropped, 1)
+}
+
+// emit sends elem to the output of the stage according to its overflow strategy.
+// It returns false if the stage must stop, because the tomb is dying, the
+// stage was cancelled or the output overflowed with OverflowFail.
+func (s *stage) emit(elem interface{}) bool {
+	select {
+	case <-s.Cancelled():
+		return false
+	default:
+	}
+	switch s.opts.overflow {
+	case OverflowDropNewest:
+		select {
+		case s.out <- elem:
+			s.processed()
+		default:
+			s.drop()
+		}
+		return true
+	case OverflowDropOldest:
+		if cap(s.out) == 0 {
+			select {
+			case s.out <- elem:
+				s.processed()
+			default:
+				s.drop()
+			}
+			return true
+		}
+		for s.t.Alive() {
+			select {
+			case s.out <- elem:
+				s.processed()
+				return true
+			default:
+			}
+			select {
+			case <-s.out:
+				s.drop()
+			default:
+			}
+		}
+		return false
+	case OverflowFail:
+		select {
+		case s.out <- elem:
+			s.processed()
+			return true
+		default:
+			s.t.Kill(&OverflowError{s.opts.name, cap(s.out)})
+			return false
+		}
+	default:
+		select {
+		case s.out <- elem:
+			s.processed()
+			return true
+		case <-s.Cancelled():
+			return false
+		case <-s.t.Dying():
+			return false
+		}
+	}
+}
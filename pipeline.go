@@ -0,0 +1,211 @@
+package tombstreams
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"gopkg.in/tomb.v2"
+)
+
+// ErrDrainTimeout is the reason of death of a pipeline that did not finish
+// draining in time, see Drain.
+var ErrDrainTimeout = errors.New("tombstreams: drain timeout")
+
+// ErrNotRunning is returned by the methods of a Pipeline that wait for it
+// when it was not started, see Run.
+var ErrNotRunning = errors.New("tombstreams: pipeline is not running")
+
+// BuildFunc connects the stages of a Pipeline, see NewPipeline.
+type BuildFunc func(p *Pipeline) error
+
+// Pipeline owns the tomb of a set of connected stages.
+// The stages are connected by a BuildFunc running on a goroutine of the tomb,
+// so a blocking To needs no wrapping, and the outcome is read with Wait.
+//
+//	p := tombstreams.NewPipeline(func(p *tombstreams.Pipeline) error {
+//		t := p.Tomb()
+//		source := p.Source(tombstreams.NewChanSource(t, in))
+//		source.Via(p.Stage(tombstreams.NewMap(t, mapFunc, 2))).To(tombstreams.NewStdoutSink(t))
+//		return nil
+//	})
+//	p.Run(ctx)
+//	err := p.Wait()
+type Pipeline struct {
+	build BuildFunc
+
+	mu  sync.Mutex
+	t   *tomb.Tomb
+	ctx context.Context
+	// killed is the reason given to Kill before Run.
+	killed  error
+	stopped bool
+	sources []Source
+	stages  []interface{ Stats() Stats }
+	// settled is closed once the signal handler has set interrupted, see HandleSignals.
+	settled chan struct{}
+	// interrupted is the outcome of a graceful shutdown by a signal.
+	interrupted error
+}
+
+// NewPipeline returns a new Pipeline instance.
+// build connects the stages on the tomb of the pipeline, see Tomb. An error
+// returned by build kills the tomb.
+func NewPipeline(build BuildFunc) *Pipeline {
+	return &Pipeline{build: build}
+}
+
+// Run creates the tomb of the pipeline as a child of ctx and starts build.
+// It does not block, see Wait. A pipeline runs only once.
+func (p *Pipeline) Run(ctx context.Context) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.t != nil {
+		panic("Pipeline is already running")
+	}
+	p.t, p.ctx = tomb.WithContext(ctx)
+	killed := p.killed
+	p.t.Go(func() error {
+		if killed != nil {
+			return killed
+		}
+		return p.build(p)
+	})
+}
+
+// Wait blocks until every stage of the pipeline has finished and returns the
+// first error, or nil if it completed or was stopped.
+// It returns ErrNotRunning if Run was not called.
+func (p *Pipeline) Wait() error {
+	t := p.Tomb()
+	if t == nil {
+		return ErrNotRunning
+	}
+	<-t.Dead()
+	p.mu.Lock()
+	settled := p.settled
+	p.mu.Unlock()
+	if settled != nil {
+		<-settled
+	}
+	if err := t.Err(); err != nil {
+		return err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.interrupted
+}
+
+// Stop stops the sources of the pipeline, see Source. The elements already
+// read keep flowing through the stages, so Wait returns once they have all
+// reached the sinks. Sources added after Stop are stopped at once.
+// See Drain to bound how long that takes.
+func (p *Pipeline) Stop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.stopped = true
+	for _, source := range p.sources {
+		stopSource(source)
+	}
+}
+
+// Drain shuts the pipeline down gracefully: it stops the sources like Stop and
+// waits for the elements in flight to reach the sinks. If the pipeline has not
+// finished within timeout, it is killed with ErrDrainTimeout and the remaining
+// elements are discarded. A timeout of 0 means no deadline.
+// It returns the result of Wait.
+func (p *Pipeline) Drain(timeout time.Duration) error {
+	p.Stop()
+	t := p.Tomb()
+	if t == nil {
+		return ErrNotRunning
+	}
+	if timeout > 0 {
+		deadline := time.NewTimer(timeout)
+		defer deadline.Stop()
+		select {
+		case <-t.Dead():
+		case <-deadline.C:
+			p.Kill(ErrDrainTimeout)
+		}
+	}
+	return p.Wait()
+}
+
+// Kill kills the tomb of the pipeline with err: every stage returns at once
+// and the elements in flight are discarded. Wait returns err, unless the
+// pipeline already failed with another error.
+// Killed before Run, the pipeline dies with err as soon as it runs, without
+// calling build.
+func (p *Pipeline) Kill(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.t == nil {
+		if p.killed == nil {
+			p.killed = err
+		}
+		return
+	}
+	p.t.Kill(err)
+}
+
+// Tomb returns the tomb of the pipeline, to create its stages with.
+// It is nil until Run is called.
+func (p *Pipeline) Tomb() *tomb.Tomb {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.t
+}
+
+// Context returns the context of the tomb, which is cancelled once the
+// pipeline is dying. Producers feeding the sources can use it to stop.
+// It is nil until Run is called.
+func (p *Pipeline) Context() context.Context {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.ctx
+}
+
+// Source registers a source, so it is stopped by Stop, and returns it.
+func (p *Pipeline) Source(source Source) Source {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.sources = append(p.sources, source)
+	if p.stopped {
+		stopSource(source)
+	}
+	return source
+}
+
+// Stage registers a stage, so its counters are reported by Summary, and returns it.
+// The value is returned as is, so it can be used inline in a Via chain.
+func (p *Pipeline) Stage(flow Flow) Flow {
+	if s, ok := flow.(interface{ Stats() Stats }); ok {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.stages = append(p.stages, s)
+	}
+	return flow
+}
+
+// Summary returns the counters of the registered stages, in registration order.
+// It can be called while the pipeline runs.
+func (p *Pipeline) Summary() []Stats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	summary := make([]Stats, len(p.stages))
+	for i, s := range p.stages {
+		summary[i] = s.Stats()
+	}
+	return summary
+}
+
+// stopSource stops a source that supports it, and cancels it otherwise.
+func stopSource(source Outlet) {
+	if s, ok := source.(interface{ Stop() }); ok {
+		s.Stop()
+		return
+	}
+	cancelUpstream(source)
+}
@@ -0,0 +1,126 @@
+package tombstreams
+
+import (
+	"math/rand"
+	"time"
+
+	"gopkg.in/tomb.v2"
+)
+
+// RetryPolicy controls how a failed call is retried.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of calls, including the first one.
+	MaxAttempts uint
+	// Backoff is the delay before the first retry.
+	Backoff time.Duration
+	// Multiplier grows the delay after every retry. Defaults to 2.
+	Multiplier float64
+	// MaxBackoff caps the delay between retries, if positive.
+	MaxBackoff time.Duration
+	// Jitter randomizes every delay by up to this fraction in either
+	// direction, so failing workers do not retry in lockstep. 0.2 means ±20%.
+	Jitter float64
+	// Retryable reports whether an error is worth retrying.
+	// All errors are retried if it is nil.
+	Retryable func(error) bool
+}
+
+// RetryMap wraps a MapFunc to retry failed calls according to the policy.
+// Backoff delays end as soon as the tomb starts dying, the wrapper then
+// returns tomb.ErrDying, which does not replace the reason of the tomb's death.
+func RetryMap(t *tomb.Tomb, mapFunc MapFunc, policy RetryPolicy) MapFunc {
+	return func(elem interface{}) (interface{}, error) {
+		res, _, err := retry(t, nil, &policy, func() (interface{}, error) {
+			return mapFunc(elem)
+		})
+		return res, err
+	}
+}
+
+// RetryFlatMap wraps a FlatMapFunc to retry failed calls according to the policy.
+// See RetryMap for the cancellation behavior.
+func RetryFlatMap(t *tomb.Tomb, flatMapFunc FlatMapFunc, policy RetryPolicy) FlatMapFunc {
+	return func(elem interface{}) ([]interface{}, error) {
+		res, _, err := retry(t, nil, &policy, func() ([]interface{}, error) {
+			return flatMapFunc(elem)
+		})
+		return res, err
+	}
+}
+
+// RetryFilter wraps a FilterFunc to retry failed calls according to the policy.
+// See RetryMap for the cancellation behavior.
+func RetryFilter(t *tomb.Tomb, filterFunc FilterFunc, policy RetryPolicy) FilterFunc {
+	return func(elem interface{}) (bool, error) {
+		res, _, err := retry(t, nil, &policy, func() (bool, error) {
+			return filterFunc(elem)
+		})
+		return res, err
+	}
+}
+
+// retry calls fn until it succeeds, returns a non-retryable error or runs out
+// of attempts. It returns the last result and error, and the number of calls.
+// If the tomb starts dying, or cancelled is closed, during a backoff delay the
+// error is tomb.ErrDying.
+func retry[R any](t *tomb.Tomb, cancelled <-chan struct{}, p *RetryPolicy, fn func() (R, error)) (R, uint, error) {
+	res, err := fn()
+	attempts := uint(1)
+	backoff := p.Backoff
+	for err != nil && attempts < p.MaxAttempts {
+		if p.Retryable != nil && !p.Retryable(err) {
+			break
+		}
+		if !sleep(t, cancelled, p.delay(backoff)) {
+			var zero R
+			return zero, attempts, tomb.ErrDying
+		}
+		backoff = p.next(backoff)
+		res, err = fn()
+		attempts++
+	}
+	return res, attempts, err
+}
+
+// delay applies the jitter to a backoff.
+func (p *RetryPolicy) delay(backoff time.Duration) time.Duration {
+	if p.Jitter <= 0 {
+		return backoff
+	}
+	d := time.Duration(float64(backoff) * (1 + p.Jitter*(2*rand.Float64()-1)))
+	if d < 0 {
+		return 0
+	}
+	return d
+}
+
+// next returns the backoff that follows the given one.
+func (p *RetryPolicy) next(backoff time.Duration) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier <= 0 {
+		multiplier = 2
+	}
+	backoff = time.Duration(float64(backoff) * multiplier)
+	if p.MaxBackoff > 0 && backoff > p.MaxBackoff {
+		return p.MaxBackoff
+	}
+	return backoff
+}
+
+// sleep waits for d and returns false if the tomb started dying, or
+// cancelled was closed, first. A nil cancelled is never closed.
+func sleep(t *tomb.Tomb, cancelled <-chan struct{}, d time.Duration) bool {
+	if d <= 0 {
+		return t.Alive()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-cancelled:
+		return false
+	case <-t.Dying():
+		return false
+	}
+}
@@ -0,0 +1,152 @@
+package tombstreams
+
+import "gopkg.in/tomb.v2"
+
+// AccumulateFunc combines the accumulator with the next element into the new accumulator.
+type AccumulateFunc func(acc interface{}, elem interface{}) (interface{}, error)
+
+// Scan emits the running accumulator of the incoming elements.
+//
+// in  -- 1 -- 2 ---- 3 -- 4 ------ 5 --
+//        |    |      |    |        |
+//    [-- AccumulateFunc = +, initial = 0 --]
+//        |    |      |    |        |
+// out -- 1 -- 3 ---- 6 -- 10 ----- 15 -
+type Scan struct {
+	stage
+	AccumulateF AccumulateFunc
+	initial     interface{}
+}
+
+// Verify Scan satisfies the Flow interface.
+var _ Flow = (*Scan)(nil)
+
+// NewScan returns a new Scan instance.
+// accumulateFunc is called with the accumulator, initial for the first element,
+// and the element. A failed element leaves the accumulator unchanged when the
+// error policy skips it.
+func NewScan(t *tomb.Tomb, accumulateFunc AccumulateFunc, initial interface{}, opts ...Option) *Scan {
+	scan := &Scan{
+		newStage(t, newOptions("Scan", opts, errorPolicyFeature)),
+		accumulateFunc,
+		initial,
+	}
+	if t.Alive() {
+		t.Go(scan.doStream)
+	}
+	return scan
+}
+
+func (s *Scan) doStream() error {
+	return s.accumulate(s.AccumulateF, s.initial, true, true)
+}
+
+// Fold emits the final accumulator of the incoming elements once the input is
+// closed. The initial accumulator is emitted if no element was received.
+//
+// in  -- 1 -- 2 ---- 3 -- 4 ------ 5 --|
+//        |    |      |    |        |
+//    [-- AccumulateFunc = +, initial = 0 --]
+//                                      |
+// out --------------------------------- 15
+type Fold struct {
+	stage
+	AccumulateF AccumulateFunc
+	initial     interface{}
+}
+
+// Verify Fold satisfies the Flow interface.
+var _ Flow = (*Fold)(nil)
+
+// NewFold returns a new Fold instance.
+// accumulateFunc is called with the accumulator, initial for the first element,
+// and the element.
+func NewFold(t *tomb.Tomb, accumulateFunc AccumulateFunc, initial interface{}, opts ...Option) *Fold {
+	fold := &Fold{
+		newStage(t, newOptions("Fold", opts, errorPolicyFeature)),
+		accumulateFunc,
+		initial,
+	}
+	if t.Alive() {
+		t.Go(fold.doStream)
+	}
+	return fold
+}
+
+func (f *Fold) doStream() error {
+	return f.accumulate(f.AccumulateF, f.initial, true, false)
+}
+
+// Reduce is a Fold without initial accumulator: the first element is the
+// initial accumulator. Nothing is emitted if no element was received.
+//
+// in  -- 1 -- 2 ---- 3 -- 4 ------ 5 --|
+//        |    |      |    |        |
+//    [------- AccumulateFunc = + ------]
+//                                      |
+// out --------------------------------- 15
+type Reduce struct {
+	stage
+	AccumulateF AccumulateFunc
+}
+
+// Verify Reduce satisfies the Flow interface.
+var _ Flow = (*Reduce)(nil)
+
+// NewReduce returns a new Reduce instance.
+// accumulateFunc is called with the accumulator and every element but the first.
+func NewReduce(t *tomb.Tomb, accumulateFunc AccumulateFunc, opts ...Option) *Reduce {
+	reduce := &Reduce{
+		newStage(t, newOptions("Reduce", opts, errorPolicyFeature)),
+		accumulateFunc,
+	}
+	if t.Alive() {
+		t.Go(reduce.doStream)
+	}
+	return reduce
+}
+
+func (r *Reduce) doStream() error {
+	return r.accumulate(r.AccumulateF, nil, false, false)
+}
+
+// accumulate folds the incoming elements into acc with accumulateFunc until
+// the input is closed. seeded is false while acc holds no value yet, the first
+// element then becomes acc. If running is set every accumulator is emitted,
+// otherwise only the final one.
+func (s *stage) accumulate(accumulateFunc AccumulateFunc, acc interface{}, seeded, running bool) error {
+	defer close(s.out)
+	defer s.opts.onError.close()
+
+	next := func(elem interface{}) (interface{}, error) {
+		return accumulateFunc(acc, elem)
+	}
+	for {
+		select {
+		case elem, ok := <-s.in:
+			if !ok {
+				if seeded && !running {
+					s.emit(acc)
+				}
+				return nil
+			}
+			if !seeded {
+				acc, seeded = elem, true
+			} else {
+				res, keep, err := apply(s.t, s.Cancelled(), s.opts, elem, next)
+				if err != nil {
+					return err
+				}
+				if !keep {
+					continue
+				}
+				acc = res
+			}
+			if running && !s.emit(acc) {
+				return nil
+			}
+		case <-s.t.Dying():
+			return nil
+		}
+	}
+}
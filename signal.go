@@ -0,0 +1,93 @@
+package tombstreams
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// ErrInterrupted matches, with errors.Is, the reason of death of a pipeline
+// stopped by a signal, see HandleSignals.
+var ErrInterrupted = errors.New("tombstreams: interrupted")
+
+// InterruptedError is the reason of death of a pipeline stopped by a signal.
+type InterruptedError struct {
+	// Signal is the signal that started the shutdown.
+	Signal os.Signal
+	// Forced is set when the pipeline was killed before it had drained, by a
+	// second signal or the drain deadline.
+	Forced bool
+}
+
+func (e *InterruptedError) Error() string {
+	if e.Forced {
+		return fmt.Sprintf("tombstreams: interrupted by %v, killed before draining", e.Signal)
+	}
+	return fmt.Sprintf("tombstreams: interrupted by %v", e.Signal)
+}
+
+// Is reports whether target is ErrInterrupted.
+func (e *InterruptedError) Is(target error) bool {
+	return target == ErrInterrupted
+}
+
+// HandleSignals shuts the pipeline down on the given signals, SIGINT and
+// SIGTERM by default. The first signal drains the pipeline like Drain, and a
+// second one, or the drain deadline, kills it. A timeout of 0 means no deadline.
+// Wait then returns an *InterruptedError, unless the pipeline failed with
+// another error first. A forced shutdown is also the reason of death of the
+// tomb, while a graceful one leaves it nil. It must be called after Run, and
+// returns ErrNotRunning otherwise.
+func (p *Pipeline) HandleSignals(timeout time.Duration, signals ...os.Signal) error {
+	t := p.Tomb()
+	if t == nil {
+		return ErrNotRunning
+	}
+	if len(signals) == 0 {
+		signals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+	received := make(chan os.Signal, 2)
+	signal.Notify(received, signals...)
+
+	settled := make(chan struct{})
+	p.mu.Lock()
+	p.settled = settled
+	p.mu.Unlock()
+
+	go func() {
+		defer close(settled)
+		defer signal.Stop(received)
+
+		var first os.Signal
+		var deadline <-chan time.Time
+		for {
+			select {
+			case sig := <-received:
+				if first != nil {
+					p.Kill(&InterruptedError{first, true})
+					continue
+				}
+				first = sig
+				p.Stop()
+				if timeout > 0 {
+					timer := time.NewTimer(timeout)
+					defer timer.Stop()
+					deadline = timer.C
+				}
+			case <-deadline:
+				p.Kill(&InterruptedError{first, true})
+			case <-t.Dead():
+				if first != nil {
+					p.mu.Lock()
+					p.interrupted = &InterruptedError{first, false}
+					p.mu.Unlock()
+				}
+				return
+			}
+		}
+	}()
+	return nil
+}
@@ -2,39 +2,135 @@ package tombstreams
 
 import (
 	"fmt"
+	"sync"
 
 	"gopkg.in/tomb.v2"
 )
 
-// ChanSink sends data to the output channel
+// ChanSink sends data to the output channel.
+// Out is closed once the input is closed, and the sink is done once every
+// element has been sent to Out.
+// Without a tomb, see NewChanSink, its Err is always nil and it keeps waiting
+// for Out to be read. With one, see NewTombChanSink, it stops once the tomb is
+// dying and Err is the reason.
+// A ChanSink can also be built as a struct literal with only Out set; it then
+// behaves as one returned by NewChanSink.
 type ChanSink struct {
+	*completion
 	Out chan interface{}
+	in  chan interface{}
+	t   *tomb.Tomb
+	// inBuffer is the capacity of in, see WithInputBuffer.
+	inBuffer int
+	initOnce sync.Once
 }
 
+// Verify ChanSink satisfies the Sink interface.
+var _ Sink = (*ChanSink)(nil)
+
 // NewChanSink returns a new ChanSink instance
-func NewChanSink(out chan interface{}) *ChanSink {
-	return &ChanSink{out}
+func NewChanSink(out chan interface{}, opts ...Option) *ChanSink {
+	o := newOptions("ChanSink", opts)
+	sink := &ChanSink{Out: out, inBuffer: o.inBuffer}
+	sink.init()
+	return sink
+}
+
+// NewTombChanSink returns a new ChanSink instance running on the tomb.
+func NewTombChanSink(t *tomb.Tomb, out chan interface{}, opts ...Option) *ChanSink {
+	o := newOptions("ChanSink", opts)
+	sink := &ChanSink{Out: out, t: t, inBuffer: o.inBuffer}
+	sink.init()
+	return sink
+}
+
+// init starts forwarding the input to Out, once, so a struct literal is
+// initialized on first use.
+func (ch *ChanSink) init() {
+	ch.initOnce.Do(func() {
+		ch.completion = newCompletion()
+		ch.in = make(chan interface{}, ch.inBuffer)
+		if ch.t == nil {
+			// There is no tomb, the goroutine ends once the input is closed.
+			go ch.forward()
+			return
+		}
+		if !ch.t.Alive() {
+			close(ch.Out)
+			ch.stop(ch.t)
+			return
+		}
+		ch.t.Go(ch.forwardOnTomb)
+	})
+}
+
+func (ch *ChanSink) forward() {
+	for elem := range ch.in {
+		ch.Out <- elem
+	}
+	close(ch.Out)
+	ch.finish(nil)
+}
+
+func (ch *ChanSink) forwardOnTomb() error {
+	defer ch.stop(ch.t)
+	defer close(ch.Out)
+	for {
+		select {
+		case elem, ok := <-ch.in:
+			if !ok {
+				return nil
+			}
+			select {
+			case ch.Out <- elem:
+			case <-ch.t.Dying():
+				return nil
+			}
+		case <-ch.t.Dying():
+			return nil
+		}
+	}
 }
 
 // In returns an input channel for receiving data
 func (ch *ChanSink) In() chan<- interface{} {
-	return ch.Out
+	ch.init()
+	return ch.in
+}
+
+// Done returns a channel that is closed once the sink has finished consuming.
+func (ch *ChanSink) Done() <-chan struct{} {
+	ch.init()
+	return ch.completion.Done()
+}
+
+// Err returns why the sink stopped before its input was closed, or nil if it
+// consumed every element or has not finished yet.
+func (ch *ChanSink) Err() error {
+	ch.init()
+	return ch.completion.Err()
 }
 
 // StdoutSink sends items to stdout
 type StdoutSink struct {
+	*completion
 	in chan interface{}
 }
 
+// Verify StdoutSink satisfies the Sink interface.
+var _ Sink = (*StdoutSink)(nil)
+
 // NewStdoutSink returns a new StdoutSink instance
-func NewStdoutSink(t *tomb.Tomb) *StdoutSink {
-	sink := &StdoutSink{make(chan interface{})}
+func NewStdoutSink(t *tomb.Tomb, opts ...Option) *StdoutSink {
+	o := newOptions("StdoutSink", opts)
+	sink := &StdoutSink{newCompletion(), make(chan interface{}, o.inBuffer)}
 	sink.init(t)
 	return sink
 }
 
 func (stdout *StdoutSink) init(t *tomb.Tomb) {
 	if !t.Alive() {
+		stdout.stop(t)
 		return
 	}
 	t.Go(func() error {
@@ -44,14 +140,15 @@ func (stdout *StdoutSink) init(t *tomb.Tomb) {
 				if ok {
 					fmt.Println(elem)
 				} else {
+					stdout.stop(t)
 					return nil
 				}
 			case <-t.Dying():
+				stdout.stop(t)
 				return nil
 			}
 
 		}
-		return nil
 	})
 }
 
@@ -62,18 +159,24 @@ func (stdout *StdoutSink) In() chan<- interface{} {
 
 // IgnoreSink sends items to /dev/null
 type IgnoreSink struct {
+	*completion
 	in chan interface{}
 }
 
+// Verify IgnoreSink satisfies the Sink interface.
+var _ Sink = (*IgnoreSink)(nil)
+
 // NewIgnoreSink returns a new IgnoreSink instance
-func NewIgnoreSink(t *tomb.Tomb) *IgnoreSink {
-	sink := &IgnoreSink{make(chan interface{})}
+func NewIgnoreSink(t *tomb.Tomb, opts ...Option) *IgnoreSink {
+	o := newOptions("IgnoreSink", opts)
+	sink := &IgnoreSink{newCompletion(), make(chan interface{}, o.inBuffer)}
 	sink.init(t)
 	return sink
 }
 
 func (ignore *IgnoreSink) init(t *tomb.Tomb) {
 	if !t.Alive() {
+		ignore.stop(t)
 		return
 	}
 	t.Go(func() error {
@@ -81,13 +184,14 @@ func (ignore *IgnoreSink) init(t *tomb.Tomb) {
 			select {
 			case _, ok := <-ignore.in:
 				if !ok {
+					ignore.stop(t)
 					return nil
 				}
 			case <-t.Dying():
+				ignore.stop(t)
 				return nil
 			}
 		}
-		return nil
 	})
 }
 
@@ -95,3 +199,50 @@ func (ignore *IgnoreSink) init(t *tomb.Tomb) {
 func (ignore *IgnoreSink) In() chan<- interface{} {
 	return ignore.in
 }
+
+// completion tracks the end of a sink and implements the Done and Err part
+// of the Sink interface.
+type completion struct {
+	done chan struct{}
+	err  error
+}
+
+func newCompletion() *completion {
+	return &completion{done: make(chan struct{})}
+}
+
+// Done returns a channel that is closed once the sink has finished consuming.
+func (c *completion) Done() <-chan struct{} {
+	return c.done
+}
+
+// Err returns why the sink stopped before its input was closed, or nil if it
+// consumed every element or has not finished yet.
+func (c *completion) Err() error {
+	select {
+	case <-c.done:
+		return c.err
+	default:
+		return nil
+	}
+}
+
+// finish records err and closes the Done channel. It must be called once.
+func (c *completion) finish(err error) {
+	c.err = err
+	close(c.done)
+}
+
+// stop finishes a sink whose input was closed or whose tomb is dying.
+// Upstream closes the input early when the tomb is dying, so the sink is only
+// complete if the tomb is alive; otherwise Err is the reason of death of the
+// tomb, or tomb.ErrDying if it was killed without error.
+func (c *completion) stop(t *tomb.Tomb) {
+	var err error
+	if !t.Alive() {
+		if err = t.Err(); err == nil {
+			err = tomb.ErrDying
+		}
+	}
+	c.finish(err)
+}
@@ -1,18 +1,87 @@
 package tombstreams
 
 import (
+	"sync"
+
 	"gopkg.in/tomb.v2"
 )
 
-// ChanSource streams data from the input channel
+// ChanSource streams data from the input channel.
+// It only starts reading the channel once it is connected, see Out, so a
+// source that is never connected leaves the channel alone.
+// It is cancelled when downstream needs no more elements, see Take; the
+// producer writing to the channel should then stop, see Cancelled.
+//
+// Its chainable methods, such as Map, create the stages on its tomb, see Stream.
 type ChanSource struct {
-	in <-chan interface{}
-	t  *tomb.Tomb
+	*cancellation
+	chain
+	in        <-chan interface{}
+	out       chan interface{}
+	t         *tomb.Tomb
+	startOnce sync.Once
+	stopOnce  sync.Once
+	stop      chan struct{}
+}
+
+// Verify ChanSource satisfies the Source and Cancellable interfaces.
+var (
+	_ Source      = (*ChanSource)(nil)
+	_ Cancellable = (*ChanSource)(nil)
+)
+
+// NewChanSource returns a new ChanSource instance.
+// The input channel belongs to the caller, so only WithOutputBuffer has an effect.
+func NewChanSource(t *tomb.Tomb, in <-chan interface{}, opts ...Option) *ChanSource {
+	o := newOptions("ChanSource", opts)
+	source := &ChanSource{
+		cancellation: newCancellation(),
+		in:           in,
+		out:          make(chan interface{}, o.outBuffer),
+		t:            t,
+		stop:         make(chan struct{}),
+	}
+	source.chain = chain{source, source}
+	return source
 }
 
-// NewChanSource returns a new ChanSource instance
-func NewChanSource(t *tomb.Tomb, in <-chan interface{}) *ChanSource {
-	return &ChanSource{in, t}
+func (cs *ChanSource) doStream() error {
+	defer close(cs.out)
+	for {
+		var e interface{}
+		select {
+		case elem, ok := <-cs.in:
+			if ok {
+				e = elem
+			} else {
+				return nil
+			}
+		case <-cs.stop:
+			return nil
+		case <-cs.Cancelled():
+			return nil
+		case <-cs.t.Dying():
+			return nil
+		}
+		// An element already read is delivered even if the source is stopped.
+		select {
+		case cs.out <- e:
+		case <-cs.Cancelled():
+			return nil
+		case <-cs.t.Dying():
+			return nil
+		}
+	}
+}
+
+// Stop stops reading the input channel and completes the source, so the
+// pipeline finishes once the elements already read have flowed through.
+// Unlike Cancel, it comes from the owner of the pipeline rather than from
+// downstream. It is safe to call it several times.
+func (cs *ChanSource) Stop() {
+	cs.stopOnce.Do(func() {
+		close(cs.stop)
+	})
 }
 
 // Via streams data through the given flow
@@ -21,9 +90,20 @@ func (cs *ChanSource) Via(_flow Flow) Flow {
 	return _flow
 }
 
-// Out returns an output channel for sending data
+// To streams data to the given sink
+func (cs *ChanSource) To(sink Sink) {
+	transmit(cs, sink)
+}
+
+// Out returns an output channel for sending data.
+// The first call starts reading the input channel on a goroutine of the tomb.
 func (cs *ChanSource) Out() <-chan interface{} {
-	return cs.in
+	cs.startOnce.Do(func() {
+		if cs.t.Alive() {
+			cs.t.Go(cs.doStream)
+		}
+	})
+	return cs.out
 }
 
 // Tomb returns the tomb context
@@ -0,0 +1,37 @@
+package tombstreams
+
+import "sync/atomic"
+
+// Stats holds the counters of a stage, see Pipeline.Summary.
+type Stats struct {
+	// Stage is the name of the stage, see WithName.
+	Stage string
+	// Processed is the number of elements the stage emitted.
+	Processed uint64
+	// Failed is the number of elements the stage function failed on, once
+	// retries were exhausted, whatever the error policy did with them.
+	Failed uint64
+	// Dropped is the number of elements the stage discarded, see Dropped.
+	Dropped uint64
+}
+
+// counters are updated atomically by the workers of a stage.
+type counters struct {
+	processed uint64
+	failed    uint64
+	dropped   uint64
+}
+
+// Stats returns a snapshot of the counters of the stage.
+func (s *stage) Stats() Stats {
+	return Stats{
+		Stage:     s.opts.name,
+		Processed: atomic.LoadUint64(&s.opts.counters.processed),
+		Failed:    atomic.LoadUint64(&s.opts.counters.failed),
+		Dropped:   atomic.LoadUint64(&s.opts.counters.dropped),
+	}
+}
+
+func (s *stage) processed() {
+	atomic.AddUint64(&s.opts.counters.processed, 1)
+}
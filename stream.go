@@ -0,0 +1,146 @@
+package tombstreams
+
+import (
+	"time"
+
+	"gopkg.in/tomb.v2"
+)
+
+// Stream wraps an outlet with chainable methods that create a stage on the
+// tomb of the outlet, connect it and return the stage as a Stream, so a
+// simple pipeline is a single expression:
+//
+//	NewChanSource(t, in).Map(parse, 2).Filter(valid, 1).Take(10).To(sink)
+//
+// The options of every method configure the stage it creates. Through
+// connects any other flow and keeps chaining.
+//
+// A Stream is a Source, so it can be passed to Pipeline.Source, Zip or Concat.
+// It is not a Flow, it has no input: Merge, which takes Flows, needs the
+// Stream to go through a PassThrough first, see Via.
+type Stream struct {
+	chain
+}
+
+// Verify Stream satisfies the Source and Cancellable interfaces.
+var (
+	_ Source      = (*Stream)(nil)
+	_ Cancellable = (*Stream)(nil)
+)
+
+// NewStream returns a new Stream instance wrapping the outlet.
+func NewStream(outlet Outlet) *Stream {
+	return &Stream{chain{outlet, outlet}}
+}
+
+// Out returns an output channel for sending data
+func (s *Stream) Out() <-chan interface{} {
+	return s.outlet.Out()
+}
+
+// Tomb returns the tomb context
+func (s *Stream) Tomb() *tomb.Tomb {
+	return s.outlet.Tomb()
+}
+
+// Cancel cancels the wrapped outlet if it is Cancellable.
+func (s *Stream) Cancel() {
+	cancelUpstream(s.outlet)
+}
+
+// Cancelled returns the cancellation channel of the wrapped outlet, or nil if
+// it is not Cancellable.
+func (s *Stream) Cancelled() <-chan struct{} {
+	return cancelledOf(s.outlet)
+}
+
+// Stop stops the outlet the chain started from, such as a ChanSource, so a
+// Stream registered with Pipeline.Source is stopped gracefully by
+// Pipeline.Stop. An outlet that cannot be stopped is cancelled.
+func (s *Stream) Stop() {
+	stopSource(s.source)
+}
+
+// Via streams data through the given flow
+func (s *Stream) Via(flow Flow) Flow {
+	DoStream(s.outlet, flow)
+	return flow
+}
+
+// To streams data to the given sink. It blocks until the stream completes.
+func (s *Stream) To(sink Sink) {
+	transmit(s.outlet, sink)
+}
+
+// chain implements the chainable methods shared by Stream and ChanSource.
+type chain struct {
+	outlet Outlet
+	// source is the outlet the chain started from, see Stream.Stop.
+	source Outlet
+}
+
+// Through streams data through the given flow and returns it as a Stream.
+func (c chain) Through(flow Flow) *Stream {
+	DoStream(c.outlet, flow)
+	return &Stream{chain{flow, c.source}}
+}
+
+// Map streams data through a new Map, see NewMap.
+func (c chain) Map(mapFunc MapFunc, parallelism uint, opts ...Option) *Stream {
+	return c.Through(NewMap(c.outlet.Tomb(), mapFunc, parallelism, opts...))
+}
+
+// FlatMap streams data through a new FlatMap, see NewFlatMap.
+func (c chain) FlatMap(flatMapFunc FlatMapFunc, parallelism uint, opts ...Option) *Stream {
+	return c.Through(NewFlatMap(c.outlet.Tomb(), flatMapFunc, parallelism, opts...))
+}
+
+// Filter streams data through a new Filter, see NewFilter.
+func (c chain) Filter(filterFunc FilterFunc, parallelism uint, opts ...Option) *Stream {
+	return c.Through(NewFilter(c.outlet.Tomb(), filterFunc, parallelism, opts...))
+}
+
+// Take streams data through a new Take, see NewTake.
+func (c chain) Take(n uint, opts ...Option) *Stream {
+	return c.Through(NewTake(c.outlet.Tomb(), n, opts...))
+}
+
+// TakeWhile streams data through a new TakeWhile, see NewTakeWhile.
+func (c chain) TakeWhile(filterFunc FilterFunc, opts ...Option) *Stream {
+	return c.Through(NewTakeWhile(c.outlet.Tomb(), filterFunc, opts...))
+}
+
+// Skip streams data through a new Skip, see NewSkip.
+func (c chain) Skip(n uint, opts ...Option) *Stream {
+	return c.Through(NewSkip(c.outlet.Tomb(), n, opts...))
+}
+
+// SkipWhile streams data through a new SkipWhile, see NewSkipWhile.
+func (c chain) SkipWhile(filterFunc FilterFunc, opts ...Option) *Stream {
+	return c.Through(NewSkipWhile(c.outlet.Tomb(), filterFunc, opts...))
+}
+
+// Scan streams data through a new Scan, see NewScan.
+func (c chain) Scan(accumulateFunc AccumulateFunc, initial interface{}, opts ...Option) *Stream {
+	return c.Through(NewScan(c.outlet.Tomb(), accumulateFunc, initial, opts...))
+}
+
+// Fold streams data through a new Fold, see NewFold.
+func (c chain) Fold(accumulateFunc AccumulateFunc, initial interface{}, opts ...Option) *Stream {
+	return c.Through(NewFold(c.outlet.Tomb(), accumulateFunc, initial, opts...))
+}
+
+// Reduce streams data through a new Reduce, see NewReduce.
+func (c chain) Reduce(accumulateFunc AccumulateFunc, opts ...Option) *Stream {
+	return c.Through(NewReduce(c.outlet.Tomb(), accumulateFunc, opts...))
+}
+
+// Batch streams data through a new Batch, see NewBatch.
+func (c chain) Batch(maxSize uint, maxWait time.Duration, opts ...Option) *Stream {
+	return c.Through(NewBatch(c.outlet.Tomb(), maxSize, maxWait, opts...))
+}
+
+// Throttle streams data through a new Throttle, see NewThrottle.
+func (c chain) Throttle(elements uint, per time.Duration, burst uint, mode ThrottleMode, opts ...Option) *Stream {
+	return c.Through(NewThrottle(c.outlet.Tomb(), elements, per, burst, mode, opts...))
+}
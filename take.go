@@ -0,0 +1,226 @@
+package tombstreams
+
+import "gopkg.in/tomb.v2"
+
+// Take emits the first n elements and completes.
+// Once it completes it cancels upstream, so the stages and the source before
+// it stop cleanly and the tomb is not killed, see Cancellable.
+//
+// in  -- 1 -- 2 ---- 3 -- 4 ------ 5 --
+//        |    |      |
+//    [------ n = 3 ------]
+//        |    |      |
+// out -- 1 -- 2 ---- 3 --|
+type Take struct {
+	stage
+	n uint
+}
+
+// Verify Take satisfies the Flow interface.
+var _ Flow = (*Take)(nil)
+
+// NewTake returns a new Take instance.
+// n is the number of elements to emit.
+func NewTake(t *tomb.Tomb, n uint, opts ...Option) *Take {
+	take := &Take{
+		newStage(t, newOptions("Take", opts)),
+		n,
+	}
+	if t.Alive() {
+		t.Go(take.doStream)
+	}
+	return take
+}
+
+func (tk *Take) doStream() error {
+	defer close(tk.out)
+	// Once done, no more elements are needed.
+	defer tk.Cancel()
+
+	for taken := uint(0); taken < tk.n; taken++ {
+		select {
+		case elem, ok := <-tk.in:
+			if !ok {
+				return nil
+			}
+			if !tk.emit(elem) {
+				return nil
+			}
+		case <-tk.t.Dying():
+			return nil
+		}
+	}
+	return nil
+}
+
+// TakeWhile emits the elements as long as the predicate returns true, and
+// completes at the first element it returns false for. Like Take, it cancels
+// upstream once it completes.
+//
+// in  -- 1 -- 2 ---- 3 -- 4 ------ 1 --
+//        |    |      |
+//    [--- FilterFunc = x < 3 ----]
+//        |    |
+// out -- 1 -- 2 -----|
+type TakeWhile struct {
+	stage
+	FilterF FilterFunc
+}
+
+// Verify TakeWhile satisfies the Flow interface.
+var _ Flow = (*TakeWhile)(nil)
+
+// NewTakeWhile returns a new TakeWhile instance.
+// filterFunc is the predicate; elements it fails on are handled by the error
+// policy of the stage.
+func NewTakeWhile(t *tomb.Tomb, filterFunc FilterFunc, opts ...Option) *TakeWhile {
+	takeWhile := &TakeWhile{
+		newStage(t, newOptions("TakeWhile", opts, errorPolicyFeature)),
+		filterFunc,
+	}
+	if t.Alive() {
+		t.Go(takeWhile.doStream)
+	}
+	return takeWhile
+}
+
+func (tw *TakeWhile) doStream() error {
+	defer close(tw.out)
+	defer tw.opts.onError.close()
+	defer tw.Cancel()
+
+	for {
+		select {
+		case elem, ok := <-tw.in:
+			if !ok {
+				return nil
+			}
+			take, keep, err := apply(tw.t, tw.Cancelled(), tw.opts, elem, tw.FilterF)
+			if err != nil {
+				return err
+			}
+			if !keep {
+				continue
+			}
+			if !take {
+				return nil
+			}
+			if !tw.emit(elem) {
+				return nil
+			}
+		case <-tw.t.Dying():
+			return nil
+		}
+	}
+}
+
+// Skip discards the first n elements and emits the following ones.
+//
+// in  -- 1 -- 2 ---- 3 -- 4 ------ 5 --
+//        |    |      |    |        |
+//    [------ n = 3 ------]
+//                         |        |
+// out ------------------- 4 ------ 5 --
+type Skip struct {
+	stage
+	n uint
+}
+
+// Verify Skip satisfies the Flow interface.
+var _ Flow = (*Skip)(nil)
+
+// NewSkip returns a new Skip instance.
+// n is the number of elements to discard.
+func NewSkip(t *tomb.Tomb, n uint, opts ...Option) *Skip {
+	skip := &Skip{
+		newStage(t, newOptions("Skip", opts)),
+		n,
+	}
+	if t.Alive() {
+		t.Go(skip.doStream)
+	}
+	return skip
+}
+
+func (sk *Skip) doStream() error {
+	defer close(sk.out)
+
+	skipped := uint(0)
+	for {
+		select {
+		case elem, ok := <-sk.in:
+			if !ok {
+				return nil
+			}
+			if skipped < sk.n {
+				skipped++
+				continue
+			}
+			if !sk.emit(elem) {
+				return nil
+			}
+		case <-sk.t.Dying():
+			return nil
+		}
+	}
+}
+
+// SkipWhile discards the elements as long as the predicate returns true, and
+// emits every element from the first one it returns false for.
+//
+// in  -- 1 -- 2 ---- 3 -- 4 ------ 1 --
+//        |    |      |    |        |
+//    [--- FilterFunc = x < 3 ----]
+//                    |    |        |
+// out -------------- 3 -- 4 ------ 1 --
+type SkipWhile struct {
+	stage
+	FilterF FilterFunc
+}
+
+// Verify SkipWhile satisfies the Flow interface.
+var _ Flow = (*SkipWhile)(nil)
+
+// NewSkipWhile returns a new SkipWhile instance.
+// filterFunc is the predicate; elements it fails on are handled by the error
+// policy of the stage.
+func NewSkipWhile(t *tomb.Tomb, filterFunc FilterFunc, opts ...Option) *SkipWhile {
+	skipWhile := &SkipWhile{
+		newStage(t, newOptions("SkipWhile", opts, errorPolicyFeature)),
+		filterFunc,
+	}
+	if t.Alive() {
+		t.Go(skipWhile.doStream)
+	}
+	return skipWhile
+}
+
+func (sw *SkipWhile) doStream() error {
+	defer close(sw.out)
+	defer sw.opts.onError.close()
+
+	skipping := true
+	for {
+		select {
+		case elem, ok := <-sw.in:
+			if !ok {
+				return nil
+			}
+			if skipping {
+				skip, keep, err := apply(sw.t, sw.Cancelled(), sw.opts, elem, sw.FilterF)
+				if err != nil {
+					return err
+				}
+				if !keep || skip {
+					continue
+				}
+				skipping = false
+			}
+			if !sw.emit(elem) {
+				return nil
+			}
+		case <-sw.t.Dying():
+			return nil
+		}
+	}
+}
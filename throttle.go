@@ -0,0 +1,112 @@
+package tombstreams
+
+import (
+	"time"
+
+	"gopkg.in/tomb.v2"
+)
+
+// ThrottleMode defines what a Throttle does with an element that exceeds the rate limit.
+type ThrottleMode int
+
+const (
+	// ThrottleBackpressure holds the element until a token is available,
+	// which blocks upstream.
+	ThrottleBackpressure ThrottleMode = iota
+	// ThrottleDrop discards the element and counts it, see Dropped.
+	ThrottleDrop
+)
+
+// Throttle limits the throughput to elements per interval using a token bucket.
+// The bucket holds up to burst tokens, so after an idle period up to burst
+// elements pass at once.
+//
+// in  -- 1 -- 2 -- 3 -- 4 -- 5 --------------------
+//        |    |    |    |    |
+//    [------ elements = 1, per = 2 ticks -------]
+//        |         |         |
+// out -- 1 ------- 2 ------- 3 ------- 4 ------- 5 -
+type Throttle struct {
+	stage
+	interval time.Duration
+	burst    float64
+	mode     ThrottleMode
+}
+
+// Verify Throttle satisfies the Flow interface.
+var _ Flow = (*Throttle)(nil)
+
+// NewThrottle returns a new Throttle instance.
+// elements is the number of elements allowed per interval.
+// burst is the bucket capacity, a burst of 0 is treated as 1.
+// mode defines whether excess elements are held or dropped.
+func NewThrottle(t *tomb.Tomb, elements uint, per time.Duration, burst uint, mode ThrottleMode, opts ...Option) *Throttle {
+	if elements == 0 || per <= 0 {
+		panic("Throttle requires a positive rate")
+	}
+	if burst == 0 {
+		burst = 1
+	}
+	throttle := &Throttle{
+		stage:    newStage(t, newOptions("Throttle", opts)),
+		interval: per / time.Duration(elements),
+		burst:    float64(burst),
+		mode:     mode,
+	}
+	if t.Alive() {
+		t.Go(throttle.doStream)
+	}
+	return throttle
+}
+
+func (th *Throttle) doStream() error {
+	defer close(th.out)
+
+	tokens := th.burst
+	last := time.Now()
+	refill := func() {
+		now := time.Now()
+		tokens += float64(now.Sub(last)) / float64(th.interval)
+		if tokens > th.burst {
+			tokens = th.burst
+		}
+		last = now
+	}
+
+	for {
+		var e interface{}
+		select {
+		case elem, ok := <-th.in:
+			if !ok {
+				return nil
+			}
+			e = elem
+		case <-th.t.Dying():
+			return nil
+		}
+
+		refill()
+		if tokens < 1 {
+			if th.mode == ThrottleDrop {
+				th.drop()
+				continue
+			}
+			wait := time.NewTimer(time.Duration((1 - tokens) * float64(th.interval)))
+			select {
+			case <-wait.C:
+			case <-th.Cancelled():
+				wait.Stop()
+				return nil
+			case <-th.t.Dying():
+				wait.Stop()
+				return nil
+			}
+			refill()
+		}
+		tokens--
+
+		if !th.emit(e) {
+			return nil
+		}
+	}
+}
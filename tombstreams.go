@@ -15,10 +15,23 @@ type Outlet interface {
 	Tomb() *tomb.Tomb
 }
 
+// Cancellable is implemented by the stages that can be stopped by downstream
+// without killing the tomb, see Take. A cancelled stage stops reading its
+// input, closes its output and cancels its own upstream. The cancellation
+// travels through Via chains and junctions up to the sources, so a pipeline
+// that has read enough completes with a nil error, unlike a failed one.
+type Cancellable interface {
+	// Cancel tells the stage that no more elements are needed downstream.
+	Cancel()
+	// Cancelled returns a channel that is closed once the stage is cancelled.
+	Cancelled() <-chan struct{}
+}
+
 // Source is a set of stream processing steps that has one open output.
 type Source interface {
 	Outlet
 	Via(Flow) Flow
+	To(Sink)
 }
 
 // Flow is a set of stream processing steps that has one open input and one open output.
@@ -33,4 +46,9 @@ type Flow interface {
 // Can be used as a Subscriber.
 type Sink interface {
 	Inlet
+	// Done returns a channel that is closed once the sink has finished consuming.
+	Done() <-chan struct{}
+	// Err returns why the sink stopped before its input was closed, such as
+	// the reason of death of its tomb, or nil. It is only set once Done is closed.
+	Err() error
 }
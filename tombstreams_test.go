@@ -2,7 +2,12 @@ package tombstreams_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -29,6 +34,53 @@ func generateCounter(ctx context.Context, count int) <-chan interface{} {
 	return out
 }
 
+// collect returns the elements of type T the sink receives until it is closed.
+func collect[T any](sink *tombstreams.ChanSink) []T {
+	actual := make([]T, 0)
+	for e := range sink.Out {
+		if elem, ok := e.(T); ok {
+			actual = append(actual, elem)
+		}
+	}
+	return actual
+}
+
+// plainOutlet is an Outlet that is not Cancellable.
+type plainOutlet struct {
+	out chan interface{}
+	t   *tomb.Tomb
+}
+
+func (o *plainOutlet) Out() <-chan interface{} {
+	return o.out
+}
+
+func (o *plainOutlet) Tomb() *tomb.Tomb {
+	return o.t
+}
+
+// produceUntilCancelled returns a source counting until it is cancelled,
+// and a channel closed once its producer has stopped.
+func produceUntilCancelled(tb *tomb.Tomb) (*tombstreams.ChanSource, chan struct{}) {
+	in := make(chan interface{})
+	stopped := make(chan struct{})
+	source := tombstreams.NewChanSource(tb, in)
+	go func() {
+		defer close(stopped)
+		defer close(in)
+		for i := 0; ; i++ {
+			select {
+			case in <- i:
+			case <-source.Cancelled():
+				return
+			case <-tb.Dying():
+				return
+			}
+		}
+	}()
+	return source, stopped
+}
+
 func TestMap(t *testing.T) {
 	t.Run("Normal", func(t *testing.T) {
 		size := 3
@@ -93,6 +145,62 @@ func TestMap(t *testing.T) {
 		assert.Equal(t, context.Canceled, ctx.Err())
 		assert.Less(t, len(actual), size)
 	})
+	t.Run("Ordered", func(t *testing.T) {
+		size := 20
+		mapp := func(in interface{}) (interface{}, error) {
+			// Later elements finish first.
+			time.Sleep(time.Duration(size-in.(int)) * time.Millisecond)
+			return fmt.Sprintf("Test-%d", in), nil
+		}
+
+		tb, ctx := tomb.WithContext(context.TODO())
+		source := tombstreams.NewChanSource(tb, generateCounter(ctx, size))
+		mapper := tombstreams.NewMap(tb, mapp, 4, tombstreams.Ordered())
+
+		out := make(chan interface{})
+		sink := tombstreams.NewChanSink(out)
+
+		tb.Go(func() error {
+			source.Via(mapper).To(sink)
+			return nil
+		})
+
+		expected := make([]string, 0, size)
+		for i := 0; i < size; i++ {
+			expected = append(expected, fmt.Sprintf("Test-%d", i))
+		}
+		actual := make([]string, 0, size)
+		for e := range sink.Out {
+			if s, ok := e.(string); ok {
+				actual = append(actual, s)
+			}
+		}
+		<-tb.Dead()
+
+		assert.Equal(t, nil, tb.Err())
+		assert.Equal(t, expected, actual)
+	})
+	t.Run("Ordered Error", func(t *testing.T) {
+		size := 10
+		mapp := func(in interface{}) (interface{}, error) {
+			if in == 5 {
+				return nil, fmt.Errorf("error!")
+			}
+			return in, nil
+		}
+
+		tb, ctx := tomb.WithContext(context.TODO())
+		source := tombstreams.NewChanSource(tb, generateCounter(ctx, size))
+		mapper := tombstreams.NewMap(tb, mapp, 4, tombstreams.Ordered())
+		sink := tombstreams.NewIgnoreSink(tb)
+
+		source.Via(mapper).To(sink)
+
+		<-tb.Dead()
+
+		assert.EqualError(t, tb.Err(), "error!")
+		assert.Equal(t, context.Canceled, ctx.Err())
+	})
 	t.Run("Context Canceled", func(t *testing.T) {
 		size := 6
 		mapp := func(in interface{}) (interface{}, error) {
@@ -100,7 +208,8 @@ func TestMap(t *testing.T) {
 			return fmt.Sprintf("Test-%d", in), nil
 		}
 
-		incomingCtx, _ := context.WithTimeout(context.TODO(), 1*time.Millisecond)
+		incomingCtx, cancel := context.WithTimeout(context.TODO(), 1*time.Millisecond)
+		defer cancel()
 		tb, ctx := tomb.WithContext(incomingCtx)
 		source := tombstreams.NewChanSource(tb, generateCounter(ctx, size))
 		mapper := tombstreams.NewMap(tb, mapp, 2)
@@ -191,6 +300,40 @@ func TestFlatMap(t *testing.T) {
 		assert.Equal(t, context.Canceled, ctx.Err())
 		assert.Less(t, len(actual), size*2)
 	})
+	t.Run("Ordered", func(t *testing.T) {
+		size := 10
+		mapp := func(in interface{}) ([]interface{}, error) {
+			time.Sleep(time.Duration(size-in.(int)) * time.Millisecond)
+			return []interface{}{fmt.Sprintf("A-%d", in), fmt.Sprintf("B-%d", in)}, nil
+		}
+
+		tb, ctx := tomb.WithContext(context.TODO())
+		source := tombstreams.NewChanSource(tb, generateCounter(ctx, size))
+		mapper := tombstreams.NewFlatMap(tb, mapp, 3, tombstreams.Ordered())
+
+		out := make(chan interface{})
+		sink := tombstreams.NewChanSink(out)
+
+		tb.Go(func() error {
+			source.Via(mapper).To(sink)
+			return nil
+		})
+
+		expected := make([]string, 0, size*2)
+		for i := 0; i < size; i++ {
+			expected = append(expected, fmt.Sprintf("A-%d", i), fmt.Sprintf("B-%d", i))
+		}
+		actual := make([]string, 0, size*2)
+		for e := range sink.Out {
+			if s, ok := e.(string); ok {
+				actual = append(actual, s)
+			}
+		}
+		<-tb.Dead()
+
+		assert.Equal(t, nil, tb.Err())
+		assert.Equal(t, expected, actual)
+	})
 }
 
 func TestFilter(t *testing.T) {
@@ -384,3 +527,2235 @@ func TestPipeline(t *testing.T) {
 		assert.Equal(t, context.Canceled, ctx.Err())
 	})
 }
+
+func TestWindow(t *testing.T) {
+	t.Run("Tumbling Flush", func(t *testing.T) {
+		tb, ctx := tomb.WithContext(context.TODO())
+		source := tombstreams.NewChanSource(tb, generateCounter(ctx, 3))
+		window := tombstreams.NewTumblingWindow(tb, time.Hour)
+		sink := tombstreams.NewChanSink(make(chan interface{}))
+
+		tb.Go(func() error {
+			source.Via(window).To(sink)
+			return nil
+		})
+
+		actual := collect[[]interface{}](sink)
+		<-tb.Dead()
+
+		assert.Equal(t, nil, tb.Err())
+		assert.Equal(t, [][]interface{}{{0, 1, 2}}, actual)
+	})
+	t.Run("Tumbling", func(t *testing.T) {
+		in := make(chan interface{})
+		go func() {
+			defer close(in)
+			in <- 1
+			in <- 2
+			time.Sleep(60 * time.Millisecond)
+			in <- 3
+		}()
+
+		tb := &tomb.Tomb{}
+		source := tombstreams.NewChanSource(tb, in)
+		window := tombstreams.NewTumblingWindow(tb, 40*time.Millisecond)
+		sink := tombstreams.NewChanSink(make(chan interface{}))
+
+		tb.Go(func() error {
+			source.Via(window).To(sink)
+			return nil
+		})
+
+		actual := collect[[]interface{}](sink)
+		<-tb.Dead()
+
+		assert.Equal(t, nil, tb.Err())
+		assert.Equal(t, [][]interface{}{{1, 2}, {3}}, actual)
+	})
+	t.Run("Sliding Flush", func(t *testing.T) {
+		tb, ctx := tomb.WithContext(context.TODO())
+		source := tombstreams.NewChanSource(tb, generateCounter(ctx, 3))
+		window := tombstreams.NewSlidingWindow(tb, time.Hour, time.Minute)
+		sink := tombstreams.NewChanSink(make(chan interface{}))
+
+		tb.Go(func() error {
+			source.Via(window).To(sink)
+			return nil
+		})
+
+		actual := collect[[]interface{}](sink)
+		<-tb.Dead()
+
+		assert.Equal(t, nil, tb.Err())
+		assert.Equal(t, [][]interface{}{{0, 1, 2}}, actual)
+	})
+	t.Run("Sliding", func(t *testing.T) {
+		in := make(chan interface{})
+		go func() {
+			defer close(in)
+			in <- 1
+			time.Sleep(150 * time.Millisecond)
+			in <- 2
+			time.Sleep(200 * time.Millisecond)
+		}()
+
+		tb := &tomb.Tomb{}
+		source := tombstreams.NewChanSource(tb, in)
+		window := tombstreams.NewSlidingWindow(tb, 250*time.Millisecond, 100*time.Millisecond)
+		sink := tombstreams.NewChanSink(make(chan interface{}))
+
+		tb.Go(func() error {
+			source.Via(window).To(sink)
+			return nil
+		})
+
+		actual := collect[[]interface{}](sink)
+		<-tb.Dead()
+
+		assert.Equal(t, nil, tb.Err())
+		assert.Equal(t, [][]interface{}{{1}, {1, 2}, {2}}, actual)
+	})
+	t.Run("Sliding Overlap", func(t *testing.T) {
+		in := make(chan interface{})
+		go func() {
+			defer close(in)
+			in <- 1
+			time.Sleep(350 * time.Millisecond)
+		}()
+
+		tb := &tomb.Tomb{}
+		source := tombstreams.NewChanSource(tb, in)
+		window := tombstreams.NewSlidingWindow(tb, 350*time.Millisecond, 100*time.Millisecond)
+		sink := tombstreams.NewChanSink(make(chan interface{}))
+
+		tb.Go(func() error {
+			source.Via(window).To(sink)
+			return nil
+		})
+
+		actual := collect[[]interface{}](sink)
+		<-tb.Dead()
+
+		assert.Equal(t, nil, tb.Err())
+		assert.Equal(t, [][]interface{}{{1}, {1}, {1}}, actual)
+	})
+	t.Run("Session", func(t *testing.T) {
+		in := make(chan interface{})
+		go func() {
+			defer close(in)
+			in <- 1
+			in <- 2
+			time.Sleep(60 * time.Millisecond)
+			in <- 3
+			time.Sleep(60 * time.Millisecond)
+			in <- 4
+		}()
+
+		tb := &tomb.Tomb{}
+		source := tombstreams.NewChanSource(tb, in)
+		window := tombstreams.NewSessionWindow(tb, 20*time.Millisecond)
+		sink := tombstreams.NewChanSink(make(chan interface{}))
+
+		tb.Go(func() error {
+			source.Via(window).To(sink)
+			return nil
+		})
+
+		actual := collect[[]interface{}](sink)
+		<-tb.Dead()
+
+		assert.Equal(t, nil, tb.Err())
+		assert.Equal(t, [][]interface{}{{1, 2}, {3}, {4}}, actual)
+	})
+	t.Run("Killed", func(t *testing.T) {
+		tb := &tomb.Tomb{}
+		source := tombstreams.NewChanSource(tb, make(chan interface{}))
+		window := tombstreams.NewSessionWindow(tb, time.Hour)
+		sink := tombstreams.NewIgnoreSink(tb)
+
+		tb.Go(func() error {
+			source.Via(window).To(sink)
+			return nil
+		})
+		tb.Kill(fmt.Errorf("killed"))
+
+		<-tb.Dead()
+
+		assert.EqualError(t, tb.Err(), "killed")
+	})
+	t.Run("Invalid", func(t *testing.T) {
+		tb := &tomb.Tomb{}
+
+		assert.PanicsWithValue(t, "TumblingWindow size must be positive", func() {
+			tombstreams.NewTumblingWindow(tb, 0)
+		})
+		assert.PanicsWithValue(t, "SlidingWindow slide must be positive", func() {
+			tombstreams.NewSlidingWindow(tb, time.Second, 0)
+		})
+		assert.PanicsWithValue(t, "SessionWindow gap must be positive", func() {
+			tombstreams.NewSessionWindow(tb, -time.Second)
+		})
+	})
+}
+
+func TestBatch(t *testing.T) {
+	t.Run("Max Size", func(t *testing.T) {
+		tb, ctx := tomb.WithContext(context.TODO())
+		source := tombstreams.NewChanSource(tb, generateCounter(ctx, 5))
+		batch := tombstreams.NewBatch(tb, 2, time.Hour)
+		sink := tombstreams.NewChanSink(make(chan interface{}))
+
+		tb.Go(func() error {
+			source.Via(batch).To(sink)
+			return nil
+		})
+
+		actual := collect[[]interface{}](sink)
+		<-tb.Dead()
+
+		assert.Equal(t, nil, tb.Err())
+		assert.Equal(t, [][]interface{}{{0, 1}, {2, 3}, {4}}, actual)
+	})
+	t.Run("Max Wait", func(t *testing.T) {
+		in := make(chan interface{})
+		go func() {
+			defer close(in)
+			in <- 1
+			time.Sleep(60 * time.Millisecond)
+			in <- 2
+			in <- 3
+			in <- 4
+			time.Sleep(60 * time.Millisecond)
+		}()
+
+		tb := &tomb.Tomb{}
+		source := tombstreams.NewChanSource(tb, in)
+		batch := tombstreams.NewBatch(tb, 2, 20*time.Millisecond)
+		sink := tombstreams.NewChanSink(make(chan interface{}))
+
+		tb.Go(func() error {
+			source.Via(batch).To(sink)
+			return nil
+		})
+
+		actual := collect[[]interface{}](sink)
+		<-tb.Dead()
+
+		assert.Equal(t, nil, tb.Err())
+		assert.Equal(t, [][]interface{}{{1}, {2, 3}, {4}}, actual)
+	})
+}
+
+func TestThrottle(t *testing.T) {
+	t.Run("Backpressure", func(t *testing.T) {
+		size := 5
+		tb, ctx := tomb.WithContext(context.TODO())
+		source := tombstreams.NewChanSource(tb, generateCounter(ctx, size))
+		throttle := tombstreams.NewThrottle(tb, 1, 20*time.Millisecond, 1, tombstreams.ThrottleBackpressure)
+
+		out := make(chan interface{})
+		sink := tombstreams.NewChanSink(out)
+
+		start := time.Now()
+		tb.Go(func() error {
+			source.Via(throttle).To(sink)
+			return nil
+		})
+
+		actual := make([]int, 0, size)
+		for e := range sink.Out {
+			if i, ok := e.(int); ok {
+				actual = append(actual, i)
+			}
+		}
+		<-tb.Dead()
+
+		assert.Equal(t, nil, tb.Err())
+		assert.Equal(t, []int{0, 1, 2, 3, 4}, actual)
+		assert.GreaterOrEqual(t, time.Since(start), 80*time.Millisecond)
+		assert.Equal(t, uint64(0), throttle.Dropped())
+	})
+	t.Run("Drop", func(t *testing.T) {
+		size := 10
+		tb, ctx := tomb.WithContext(context.TODO())
+		source := tombstreams.NewChanSource(tb, generateCounter(ctx, size))
+		throttle := tombstreams.NewThrottle(tb, 1, time.Hour, 3, tombstreams.ThrottleDrop)
+
+		out := make(chan interface{})
+		sink := tombstreams.NewChanSink(out)
+
+		tb.Go(func() error {
+			source.Via(throttle).To(sink)
+			return nil
+		})
+
+		actual := make([]int, 0, size)
+		for e := range sink.Out {
+			if i, ok := e.(int); ok {
+				actual = append(actual, i)
+			}
+		}
+		<-tb.Dead()
+
+		assert.Equal(t, nil, tb.Err())
+		assert.Equal(t, []int{0, 1, 2}, actual)
+		assert.Equal(t, uint64(7), throttle.Dropped())
+	})
+	t.Run("Killed", func(t *testing.T) {
+		tb, ctx := tomb.WithContext(context.TODO())
+		source := tombstreams.NewChanSource(tb, generateCounter(ctx, 10))
+		throttle := tombstreams.NewThrottle(tb, 1, time.Hour, 1, tombstreams.ThrottleBackpressure)
+		sink := tombstreams.NewIgnoreSink(tb)
+
+		tb.Go(func() error {
+			source.Via(throttle).To(sink)
+			return nil
+		})
+		time.Sleep(10 * time.Millisecond)
+		tb.Kill(fmt.Errorf("killed"))
+
+		<-tb.Dead()
+
+		assert.EqualError(t, tb.Err(), "killed")
+	})
+}
+
+func TestErrorPolicy(t *testing.T) {
+	failOnOdd := func(in interface{}) (interface{}, error) {
+		if in.(int)%2 == 1 {
+			return nil, fmt.Errorf("odd %d", in)
+		}
+		return fmt.Sprintf("Test-%d", in), nil
+	}
+
+	t.Run("Unsupported", func(t *testing.T) {
+		tb := &tomb.Tomb{}
+		deadLetters := tombstreams.NewPassThrough(tb)
+		defer tb.Kill(nil)
+
+		assert.PanicsWithValue(t, "Batch does not support the OnError options", func() {
+			tombstreams.NewBatch(tb, 2, time.Hour, tombstreams.OnErrorDeadLetter(deadLetters))
+		})
+		assert.PanicsWithValue(t, "FanOut does not support the OnError options", func() {
+			tombstreams.FanOut(deadLetters, 2, tombstreams.OnErrorResume())
+		})
+		assert.PanicsWithValue(t, "Take does not support the Ordered option", func() {
+			tombstreams.NewTake(tb, 1, tombstreams.Ordered())
+		})
+	})
+	t.Run("Resume", func(t *testing.T) {
+		size := 5
+		tb, ctx := tomb.WithContext(context.TODO())
+		source := tombstreams.NewChanSource(tb, generateCounter(ctx, size))
+		mapper := tombstreams.NewMap(tb, failOnOdd, 2, tombstreams.OnErrorResume())
+
+		out := make(chan interface{})
+		sink := tombstreams.NewChanSink(out)
+
+		tb.Go(func() error {
+			source.Via(mapper).To(sink)
+			return nil
+		})
+
+		actual := make([]string, 0, size)
+		for e := range sink.Out {
+			if s, ok := e.(string); ok {
+				actual = append(actual, s)
+			}
+		}
+		<-tb.Dead()
+
+		assert.Equal(t, nil, tb.Err())
+		assert.ElementsMatch(t, []string{"Test-0", "Test-2", "Test-4"}, actual)
+	})
+	t.Run("Retry", func(t *testing.T) {
+		size := 3
+		var mu sync.Mutex
+		attempts := make(map[interface{}]int)
+		flaky := func(in interface{}) ([]interface{}, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			attempts[in]++
+			if attempts[in] < 3 {
+				return nil, fmt.Errorf("flaky")
+			}
+			return []interface{}{in}, nil
+		}
+
+		tb, ctx := tomb.WithContext(context.TODO())
+		source := tombstreams.NewChanSource(tb, generateCounter(ctx, size))
+		flat := tombstreams.NewFlatMap(tb, flaky, 2,
+			tombstreams.OnErrorRetry(tombstreams.RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond}))
+
+		out := make(chan interface{})
+		sink := tombstreams.NewChanSink(out)
+
+		tb.Go(func() error {
+			source.Via(flat).To(sink)
+			return nil
+		})
+
+		actual := make([]int, 0, size)
+		for e := range sink.Out {
+			if i, ok := e.(int); ok {
+				actual = append(actual, i)
+			}
+		}
+		<-tb.Dead()
+
+		assert.Equal(t, nil, tb.Err())
+		assert.ElementsMatch(t, []int{0, 1, 2}, actual)
+		assert.Equal(t, map[interface{}]int{0: 3, 1: 3, 2: 3}, attempts)
+	})
+	t.Run("Retry Exhausted", func(t *testing.T) {
+		tb, ctx := tomb.WithContext(context.TODO())
+		source := tombstreams.NewChanSource(tb, generateCounter(ctx, 3))
+		mapper := tombstreams.NewMap(tb, failOnOdd, 1, tombstreams.Ordered(),
+			tombstreams.OnErrorRetry(tombstreams.RetryPolicy{MaxAttempts: 2, Backoff: time.Millisecond}))
+		sink := tombstreams.NewIgnoreSink(tb)
+
+		source.Via(mapper).To(sink)
+
+		<-tb.Dead()
+
+		assert.EqualError(t, tb.Err(), "odd 1")
+	})
+	t.Run("Dead Letter", func(t *testing.T) {
+		size := 5
+		filter := func(in interface{}) (bool, error) {
+			if in.(int)%2 == 1 {
+				return false, fmt.Errorf("odd %d", in)
+			}
+			return true, nil
+		}
+
+		tb, ctx := tomb.WithContext(context.TODO())
+		source := tombstreams.NewChanSource(tb, generateCounter(ctx, size))
+		deadLetters := tombstreams.NewChanSink(make(chan interface{}, size))
+		filt := tombstreams.NewFilter(tb, filter, 2, tombstreams.OnErrorDeadLetter(deadLetters))
+
+		out := make(chan interface{})
+		sink := tombstreams.NewChanSink(out)
+
+		tb.Go(func() error {
+			source.Via(filt).To(sink)
+			return nil
+		})
+
+		actual := make([]int, 0, size)
+		for e := range sink.Out {
+			if i, ok := e.(int); ok {
+				actual = append(actual, i)
+			}
+		}
+		<-tb.Dead()
+
+		failed := make([]string, 0, size)
+		for e := range deadLetters.Out {
+			if dl, ok := e.(tombstreams.DeadLetter); ok {
+				failed = append(failed, fmt.Sprintf("%s %v: %v", dl.Stage, dl.Element, dl.Err))
+			}
+		}
+
+		assert.Equal(t, nil, tb.Err())
+		assert.ElementsMatch(t, []int{0, 2, 4}, actual)
+		assert.ElementsMatch(t, []string{"Filter 1: odd 1", "Filter 3: odd 3"}, failed)
+	})
+	t.Run("Dead Letter Outlet", func(t *testing.T) {
+		size := 4
+		tb, ctx := tomb.WithContext(context.TODO())
+		source := tombstreams.NewChanSource(tb, generateCounter(ctx, size))
+		deadLetters := tombstreams.NewPassThrough(tb)
+		mapper := tombstreams.NewMap(tb, failOnOdd, 2,
+			tombstreams.WithName("parse"),
+			tombstreams.OnErrorRetry(tombstreams.RetryPolicy{MaxAttempts: 2}),
+			tombstreams.OnErrorDeadLetter(deadLetters))
+		sink := tombstreams.NewIgnoreSink(tb)
+
+		out := make(chan interface{})
+		failures := tombstreams.NewChanSink(out)
+
+		tb.Go(func() error {
+			source.Via(mapper).To(sink)
+			return nil
+		})
+		tb.Go(func() error {
+			deadLetters.To(failures)
+			return nil
+		})
+
+		failed := make([]tombstreams.DeadLetter, 0, size)
+		for e := range failures.Out {
+			if dl, ok := e.(tombstreams.DeadLetter); ok {
+				failed = append(failed, dl)
+			}
+		}
+		<-tb.Dead()
+
+		assert.Equal(t, nil, tb.Err())
+		if assert.Len(t, failed, 2) {
+			for _, dl := range failed {
+				assert.Equal(t, "parse", dl.Stage)
+				assert.Equal(t, uint(2), dl.Attempts)
+				assert.False(t, dl.Time.IsZero())
+				assert.EqualError(t, dl, fmt.Sprintf("parse: element %v failed after 2 attempt(s): odd %v", dl.Element, dl.Element))
+			}
+		}
+	})
+	t.Run("Dead Letter Cancelled", func(t *testing.T) {
+		size := 10
+		tb, ctx := tomb.WithContext(context.TODO())
+		source := tombstreams.NewChanSource(tb, generateCounter(ctx, size))
+		deadLetters := tombstreams.NewPassThrough(tb)
+		mapper := tombstreams.NewMap(tb, failOnOdd, 1, tombstreams.OnErrorDeadLetter(deadLetters))
+		sink := tombstreams.NewChanSink(make(chan interface{}))
+		failures := tombstreams.NewChanSink(make(chan interface{}))
+
+		tb.Go(func() error {
+			source.Via(mapper).To(sink)
+			return nil
+		})
+		tb.Go(func() error {
+			// Only the first dead letter is read.
+			deadLetters.Via(tombstreams.NewTake(tb, 1)).To(failures)
+			return nil
+		})
+
+		go collect[interface{}](failures)
+		actual := collect[string](sink)
+		<-tb.Dead()
+
+		assert.Equal(t, nil, tb.Err())
+		assert.Len(t, actual, size/2)
+	})
+}
+
+func TestRetry(t *testing.T) {
+	t.Run("Retryable", func(t *testing.T) {
+		errFatal := fmt.Errorf("fatal")
+		var mu sync.Mutex
+		attempts := make(map[interface{}]int)
+		mapp := func(in interface{}) (interface{}, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			attempts[in]++
+			if in == 2 {
+				return nil, errFatal
+			}
+			if attempts[in] < 3 {
+				return nil, fmt.Errorf("flaky")
+			}
+			return in, nil
+		}
+		policy := tombstreams.RetryPolicy{
+			MaxAttempts: 5,
+			Backoff:     time.Millisecond,
+			MaxBackoff:  2 * time.Millisecond,
+			Jitter:      0.5,
+			Retryable: func(err error) bool {
+				return err != errFatal
+			},
+		}
+
+		tb, ctx := tomb.WithContext(context.TODO())
+		source := tombstreams.NewChanSource(tb, generateCounter(ctx, 3))
+		mapper := tombstreams.NewMap(tb, tombstreams.RetryMap(tb, mapp, policy), 1)
+		sink := tombstreams.NewIgnoreSink(tb)
+
+		source.Via(mapper).To(sink)
+
+		<-tb.Dead()
+
+		assert.Equal(t, errFatal, tb.Err())
+		assert.Equal(t, map[interface{}]int{0: 3, 1: 3, 2: 1}, attempts)
+	})
+	t.Run("Filter", func(t *testing.T) {
+		calls := 0
+		filter := func(in interface{}) (bool, error) {
+			calls++
+			if calls < 2 {
+				return false, fmt.Errorf("flaky")
+			}
+			return true, nil
+		}
+
+		tb := &tomb.Tomb{}
+		retrying := tombstreams.RetryFilter(tb, filter, tombstreams.RetryPolicy{MaxAttempts: 2})
+		include, err := retrying(1)
+
+		assert.NoError(t, err)
+		assert.True(t, include)
+		assert.Equal(t, 2, calls)
+	})
+	t.Run("Killed During Backoff", func(t *testing.T) {
+		flatten := func(in interface{}) ([]interface{}, error) {
+			return nil, fmt.Errorf("flaky")
+		}
+
+		tb, ctx := tomb.WithContext(context.TODO())
+		source := tombstreams.NewChanSource(tb, generateCounter(ctx, 3))
+		policy := tombstreams.RetryPolicy{MaxAttempts: 10, Backoff: time.Hour}
+		flat := tombstreams.NewFlatMap(tb, tombstreams.RetryFlatMap(tb, flatten, policy), 1)
+		sink := tombstreams.NewIgnoreSink(tb)
+
+		tb.Go(func() error {
+			source.Via(flat).To(sink)
+			return nil
+		})
+		time.Sleep(10 * time.Millisecond)
+		tb.Kill(fmt.Errorf("killed"))
+
+		select {
+		case <-tb.Dead():
+		case <-time.After(time.Second):
+			t.Fatal("retry backoff ignored the dying tomb")
+		}
+		assert.EqualError(t, tb.Err(), "killed")
+	})
+}
+
+func TestBuffer(t *testing.T) {
+	t.Run("Absorbs Burst", func(t *testing.T) {
+		size := 5
+		var processed int32
+		mapp := func(in interface{}) (interface{}, error) {
+			atomic.AddInt32(&processed, 1)
+			return in, nil
+		}
+
+		tb, ctx := tomb.WithContext(context.TODO())
+		source := tombstreams.NewChanSource(tb, generateCounter(ctx, size))
+		mapper := tombstreams.NewMap(tb, mapp, 1, tombstreams.WithOutputBuffer(size))
+		pass := tombstreams.NewPassThrough(tb, tombstreams.WithBuffer(1))
+
+		out := make(chan interface{})
+		sink := tombstreams.NewChanSink(out)
+
+		tb.Go(func() error {
+			source.Via(mapper).Via(pass).To(sink)
+			return nil
+		})
+
+		// Nothing reads the sink yet, only the buffers let the map run ahead.
+		assert.Eventually(t, func() bool {
+			return atomic.LoadInt32(&processed) == int32(size)
+		}, time.Second, time.Millisecond)
+
+		actual := make([]int, 0, size)
+		for e := range sink.Out {
+			if i, ok := e.(int); ok {
+				actual = append(actual, i)
+			}
+		}
+		<-tb.Dead()
+
+		assert.Equal(t, nil, tb.Err())
+		assert.Equal(t, []int{0, 1, 2, 3, 4}, actual)
+	})
+	t.Run("Source And Sink", func(t *testing.T) {
+		tb, ctx := tomb.WithContext(context.TODO())
+		// One more element than the buffer holds, so the source keeps running.
+		source := tombstreams.NewChanSource(tb, generateCounter(ctx, 4), tombstreams.WithOutputBuffer(3))
+
+		// Nothing reads the source yet, only its buffer lets it run ahead.
+		assert.Eventually(t, func() bool {
+			return len(source.Out()) == 3
+		}, time.Second, time.Millisecond)
+
+		sink := tombstreams.NewChanSink(make(chan interface{}), tombstreams.WithInputBuffer(3))
+		tb.Go(func() error {
+			source.To(sink)
+			return nil
+		})
+
+		assert.Equal(t, []interface{}{0, 1, 2, 3}, collect[interface{}](sink))
+		<-tb.Dead()
+	})
+	t.Run("Negative Capacity", func(t *testing.T) {
+		assert.PanicsWithValue(t, "WithInputBuffer capacity must not be negative", func() {
+			tombstreams.WithInputBuffer(-1)
+		})
+		assert.PanicsWithValue(t, "WithOutputBuffer capacity must not be negative", func() {
+			tombstreams.WithOutputBuffer(-1)
+		})
+		assert.PanicsWithValue(t, "WithBuffer capacity must not be negative", func() {
+			tombstreams.WithBuffer(-1)
+		})
+	})
+}
+
+func TestOverflow(t *testing.T) {
+	run := func(strategy tombstreams.OverflowStrategy) (*tomb.Tomb, *tombstreams.PassThrough) {
+		tb, ctx := tomb.WithContext(context.TODO())
+		source := tombstreams.NewChanSource(tb, generateCounter(ctx, 10))
+		pass := tombstreams.NewPassThrough(tb, tombstreams.WithOutputBuffer(2), tombstreams.WithOverflow(strategy))
+		source.Via(pass)
+		// Keep the tomb alive once the stages are done, so the buffer is drained before it dies.
+		tb.Go(func() error {
+			<-tb.Dying()
+			return nil
+		})
+		return tb, pass
+	}
+	drain := func(tb *tomb.Tomb, outlet tombstreams.Outlet) []int {
+		sink := tombstreams.NewChanSink(make(chan interface{}))
+		tombstreams.DoStream(outlet, sink)
+		actual := collect[int](sink)
+		tb.Kill(nil)
+		return actual
+	}
+
+	t.Run("Drop Newest", func(t *testing.T) {
+		tb, pass := run(tombstreams.OverflowDropNewest)
+		assert.Eventually(t, func() bool {
+			return pass.Dropped() == 8
+		}, time.Second, time.Millisecond)
+
+		actual := drain(tb, pass)
+		<-tb.Dead()
+
+		assert.Equal(t, nil, tb.Err())
+		assert.Equal(t, []int{0, 1}, actual)
+	})
+	t.Run("Drop Oldest", func(t *testing.T) {
+		tb, pass := run(tombstreams.OverflowDropOldest)
+		assert.Eventually(t, func() bool {
+			return pass.Dropped() == 8
+		}, time.Second, time.Millisecond)
+
+		actual := drain(tb, pass)
+		<-tb.Dead()
+
+		assert.Equal(t, nil, tb.Err())
+		assert.Equal(t, []int{8, 9}, actual)
+	})
+	t.Run("Fail", func(t *testing.T) {
+		tb, pass := run(tombstreams.OverflowFail)
+		<-tb.Dead()
+
+		var overflow *tombstreams.OverflowError
+		if assert.ErrorAs(t, tb.Err(), &overflow) {
+			assert.Equal(t, "PassThrough", overflow.Stage)
+			assert.Equal(t, 2, overflow.Capacity)
+		}
+		assert.Equal(t, uint64(0), pass.Dropped())
+	})
+	t.Run("Throttle Drops", func(t *testing.T) {
+		tb, ctx := tomb.WithContext(context.TODO())
+		source := tombstreams.NewChanSource(tb, generateCounter(ctx, 10))
+		throttle := tombstreams.NewThrottle(tb, 1, time.Hour, 10, tombstreams.ThrottleBackpressure,
+			tombstreams.WithOutputBuffer(4), tombstreams.WithOverflow(tombstreams.OverflowDropNewest))
+		source.Via(throttle)
+		tb.Go(func() error {
+			<-tb.Dying()
+			return nil
+		})
+		assert.Eventually(t, func() bool {
+			return throttle.Dropped() == 6
+		}, time.Second, time.Millisecond)
+
+		actual := drain(tb, throttle)
+		<-tb.Dead()
+
+		assert.Equal(t, nil, tb.Err())
+		assert.Equal(t, []int{0, 1, 2, 3}, actual)
+	})
+}
+
+func TestGroupBy(t *testing.T) {
+	byMod := func(in interface{}) (interface{}, error) {
+		return in.(int) % 3, nil
+	}
+	groups := func(sink *tombstreams.ChanSink) map[interface{}][]int {
+		actual := make(map[interface{}][]int)
+		for _, i := range collect[int](sink) {
+			actual[i%3] = append(actual[i%3], i)
+		}
+		return actual
+	}
+
+	t.Run("Normal", func(t *testing.T) {
+		size := 30
+		tb, ctx := tomb.WithContext(context.TODO())
+		var mu sync.Mutex
+		built := make([]interface{}, 0)
+		factory := func(key interface{}, sub tombstreams.Flow) tombstreams.Flow {
+			mu.Lock()
+			built = append(built, key)
+			mu.Unlock()
+			slow := func(in interface{}) (interface{}, error) {
+				if key == 0 {
+					time.Sleep(time.Millisecond)
+				}
+				return in, nil
+			}
+			return sub.Via(tombstreams.NewMap(tb, slow, 1)).Via(tombstreams.NewPassThrough(tb))
+		}
+
+		source := tombstreams.NewChanSource(tb, generateCounter(ctx, size))
+		groupBy := tombstreams.NewGroupBy(tb, byMod, factory, 0, 0)
+
+		out := make(chan interface{})
+		sink := tombstreams.NewChanSink(out)
+
+		tb.Go(func() error {
+			source.Via(groupBy).To(sink)
+			return nil
+		})
+
+		actual := groups(sink)
+		<-tb.Dead()
+
+		assert.Equal(t, nil, tb.Err())
+		assert.ElementsMatch(t, []interface{}{0, 1, 2}, built)
+		for key, elems := range actual {
+			assert.Len(t, elems, size/3)
+			for i, e := range elems {
+				assert.Equal(t, key.(int)+3*i, e)
+			}
+		}
+	})
+	t.Run("Max Keys", func(t *testing.T) {
+		size := 12
+		tb, ctx := tomb.WithContext(context.TODO())
+		var built int32
+		factory := func(key interface{}, sub tombstreams.Flow) tombstreams.Flow {
+			atomic.AddInt32(&built, 1)
+			return sub
+		}
+
+		source := tombstreams.NewChanSource(tb, generateCounter(ctx, size))
+		groupBy := tombstreams.NewGroupBy(tb, byMod, factory, 2, 0)
+
+		out := make(chan interface{})
+		sink := tombstreams.NewChanSink(out)
+
+		tb.Go(func() error {
+			source.Via(groupBy).To(sink)
+			return nil
+		})
+
+		actual := groups(sink)
+		<-tb.Dead()
+
+		assert.Equal(t, nil, tb.Err())
+		// Keys arrive round-robin, so every element evicts the least recent key.
+		assert.Equal(t, int32(size), atomic.LoadInt32(&built))
+		assert.Equal(t, map[interface{}][]int{0: {0, 3, 6, 9}, 1: {1, 4, 7, 10}, 2: {2, 5, 8, 11}}, actual)
+	})
+	t.Run("Idle", func(t *testing.T) {
+		in := make(chan interface{})
+		go func() {
+			defer close(in)
+			in <- 1
+			time.Sleep(60 * time.Millisecond)
+			in <- 4
+		}()
+
+		tb := &tomb.Tomb{}
+		var built int32
+		factory := func(key interface{}, sub tombstreams.Flow) tombstreams.Flow {
+			atomic.AddInt32(&built, 1)
+			return sub
+		}
+
+		source := tombstreams.NewChanSource(tb, in)
+		groupBy := tombstreams.NewGroupBy(tb, byMod, factory, 0, 20*time.Millisecond)
+
+		out := make(chan interface{})
+		sink := tombstreams.NewChanSink(out)
+
+		tb.Go(func() error {
+			source.Via(groupBy).To(sink)
+			return nil
+		})
+
+		actual := groups(sink)
+		<-tb.Dead()
+
+		assert.Equal(t, nil, tb.Err())
+		assert.Equal(t, int32(2), atomic.LoadInt32(&built))
+		assert.Equal(t, map[interface{}][]int{1: {1, 4}}, actual)
+	})
+	t.Run("Tiny Idle", func(t *testing.T) {
+		size := 9
+		tb, ctx := tomb.WithContext(context.TODO())
+		source := tombstreams.NewChanSource(tb, generateCounter(ctx, size))
+		groupBy := tombstreams.NewGroupBy(tb, byMod, func(key interface{}, sub tombstreams.Flow) tombstreams.Flow {
+			return sub
+		}, 0, time.Nanosecond)
+		sink := tombstreams.NewChanSink(make(chan interface{}))
+
+		tb.Go(func() error {
+			source.Via(groupBy).To(sink)
+			return nil
+		})
+
+		actual := groups(sink)
+		<-tb.Dead()
+
+		assert.Equal(t, nil, tb.Err())
+		assert.Equal(t, map[interface{}][]int{0: {0, 3, 6}, 1: {1, 4, 7}, 2: {2, 5, 8}}, actual)
+		assert.PanicsWithValue(t, "GroupBy idle must not be negative", func() {
+			tombstreams.NewGroupBy(tb, byMod, nil, 0, -time.Second)
+		})
+	})
+	t.Run("Key Error", func(t *testing.T) {
+		keyFunc := func(in interface{}) (interface{}, error) {
+			if in == 2 {
+				return nil, fmt.Errorf("error!")
+			}
+			return in, nil
+		}
+		factory := func(key interface{}, sub tombstreams.Flow) tombstreams.Flow {
+			return sub
+		}
+
+		tb, ctx := tomb.WithContext(context.TODO())
+		source := tombstreams.NewChanSource(tb, generateCounter(ctx, 5))
+		groupBy := tombstreams.NewGroupBy(tb, keyFunc, factory, 0, 0)
+		sink := tombstreams.NewIgnoreSink(tb)
+
+		source.Via(groupBy).To(sink)
+
+		<-tb.Dead()
+
+		assert.EqualError(t, tb.Err(), "error!")
+	})
+	t.Run("Sub-Stream Take", func(t *testing.T) {
+		size := 12
+		tb, ctx := tomb.WithContext(context.TODO())
+		factory := func(key interface{}, sub tombstreams.Flow) tombstreams.Flow {
+			return sub.Via(tombstreams.NewTake(tb, 2))
+		}
+
+		source := tombstreams.NewChanSource(tb, generateCounter(ctx, size))
+		groupBy := tombstreams.NewGroupBy(tb, byMod, factory, 0, 0)
+		sink := tombstreams.NewChanSink(make(chan interface{}))
+
+		tb.Go(func() error {
+			source.Via(groupBy).To(sink)
+			return nil
+		})
+
+		actual := groups(sink)
+		<-tb.Dead()
+
+		assert.Equal(t, nil, tb.Err())
+		assert.Equal(t, map[interface{}][]int{0: {0, 3}, 1: {1, 4}, 2: {2, 5}}, actual)
+	})
+}
+
+func TestKeyedMap(t *testing.T) {
+	t.Run("Normal", func(t *testing.T) {
+		size := 40
+		users := []string{"alice", "bob", "carol", "dave", "erin"}
+		keyFunc := func(in interface{}) (interface{}, error) {
+			return users[in.(int)%len(users)], nil
+		}
+		mapp := func(in interface{}) (interface{}, error) {
+			time.Sleep(time.Duration(in.(int)%3) * time.Millisecond)
+			return in, nil
+		}
+
+		tb, ctx := tomb.WithContext(context.TODO())
+		source := tombstreams.NewChanSource(tb, generateCounter(ctx, size))
+		mapper := tombstreams.NewKeyedMap(tb, keyFunc, mapp, 4)
+
+		out := make(chan interface{})
+		sink := tombstreams.NewChanSink(out)
+
+		tb.Go(func() error {
+			source.Via(mapper).To(sink)
+			return nil
+		})
+
+		actual := make(map[string][]int)
+		for e := range sink.Out {
+			if i, ok := e.(int); ok {
+				user := users[i%len(users)]
+				actual[user] = append(actual[user], i)
+			}
+		}
+		<-tb.Dead()
+
+		assert.Equal(t, nil, tb.Err())
+		for u, user := range users {
+			expected := make([]int, 0, size/len(users))
+			for i := u; i < size; i += len(users) {
+				expected = append(expected, i)
+			}
+			assert.Equal(t, expected, actual[user])
+		}
+	})
+	t.Run("Error", func(t *testing.T) {
+		keyFunc := func(in interface{}) (interface{}, error) {
+			return in, nil
+		}
+		mapp := func(in interface{}) (interface{}, error) {
+			if in == 1 {
+				return nil, fmt.Errorf("error!")
+			}
+			return in, nil
+		}
+
+		tb, ctx := tomb.WithContext(context.TODO())
+		source := tombstreams.NewChanSource(tb, generateCounter(ctx, 3))
+		mapper := tombstreams.NewKeyedMap(tb, keyFunc, mapp, 2)
+		sink := tombstreams.NewIgnoreSink(tb)
+
+		source.Via(mapper).To(sink)
+
+		<-tb.Dead()
+
+		assert.EqualError(t, tb.Err(), "error!")
+	})
+}
+
+func TestBalance(t *testing.T) {
+	tag := func(name string, delay time.Duration) tombstreams.MapFunc {
+		return func(in interface{}) (interface{}, error) {
+			time.Sleep(delay)
+			return fmt.Sprintf("%s-%d", name, in), nil
+		}
+	}
+
+	t.Run("Round Robin", func(t *testing.T) {
+		size := 6
+		tb, ctx := tomb.WithContext(context.TODO())
+		source := tombstreams.NewChanSource(tb, generateCounter(ctx, size))
+
+		out := make(chan interface{})
+		sink := tombstreams.NewChanSink(out)
+
+		tb.Go(func() error {
+			flows := tombstreams.Balance(source, 2, tombstreams.BalanceRoundRobin)
+			a := flows[0].Via(tombstreams.NewMap(tb, tag("A", 0), 1))
+			b := flows[1].Via(tombstreams.NewMap(tb, tag("B", 0), 1))
+			tombstreams.Merge(a, b).To(sink)
+			return nil
+		})
+
+		actual := make([]string, 0, size)
+		for e := range sink.Out {
+			if s, ok := e.(string); ok {
+				actual = append(actual, s)
+			}
+		}
+		<-tb.Dead()
+
+		assert.Equal(t, nil, tb.Err())
+		assert.ElementsMatch(t, []string{"A-0", "B-1", "A-2", "B-3", "A-4", "B-5"}, actual)
+	})
+	t.Run("First Ready", func(t *testing.T) {
+		size := 40
+		tb, ctx := tomb.WithContext(context.TODO())
+		source := tombstreams.NewChanSource(tb, generateCounter(ctx, size))
+
+		out := make(chan interface{})
+		sink := tombstreams.NewChanSink(out)
+
+		tb.Go(func() error {
+			flows := tombstreams.Balance(source, 2, tombstreams.BalanceFirstReady)
+			slow := flows[0].Via(tombstreams.NewMap(tb, tag("slow", 10*time.Millisecond), 1))
+			fast := flows[1].Via(tombstreams.NewMap(tb, tag("fast", 0), 1))
+			tombstreams.Merge(slow, fast).To(sink)
+			return nil
+		})
+
+		counts := make(map[string]int)
+		seen := make(map[int]bool)
+		for e := range sink.Out {
+			var name string
+			var i int
+			if _, err := fmt.Sscanf(strings.Replace(e.(string), "-", " ", 1), "%s %d", &name, &i); err == nil {
+				counts[name]++
+				seen[i] = true
+			}
+		}
+		<-tb.Dead()
+
+		assert.Equal(t, nil, tb.Err())
+		assert.Len(t, seen, size)
+		assert.Greater(t, counts["fast"], counts["slow"])
+	})
+}
+
+func TestPartition(t *testing.T) {
+	parity := func(in interface{}) (int, error) {
+		if in == 3 {
+			return -1, nil
+		}
+		return in.(int) % 2, nil
+	}
+
+	t.Run("Normal", func(t *testing.T) {
+		size := 6
+		tb, ctx := tomb.WithContext(context.TODO())
+		source := tombstreams.NewChanSource(tb, generateCounter(ctx, size))
+		deadLetters := tombstreams.NewChanSink(make(chan interface{}, size))
+
+		even := tombstreams.NewChanSink(make(chan interface{}, size))
+		odd := tombstreams.NewChanSink(make(chan interface{}, size))
+
+		flows := tombstreams.Partition(source, 2, parity, tombstreams.OnErrorDeadLetter(deadLetters))
+		tb.Go(func() error {
+			flows[0].To(even)
+			return nil
+		})
+		tb.Go(func() error {
+			flows[1].To(odd)
+			return nil
+		})
+		<-tb.Dead()
+
+		assert.Equal(t, nil, tb.Err())
+		assert.Equal(t, []interface{}{0, 2, 4}, collect[interface{}](even))
+		assert.Equal(t, []interface{}{1, 5}, collect[interface{}](odd))
+		failed := collect[tombstreams.DeadLetter](deadLetters)
+		if assert.Len(t, failed, 1) {
+			dl := failed[0]
+			assert.Equal(t, 3, dl.Element)
+			assert.Equal(t, "Partition", dl.Stage)
+			assert.EqualError(t, dl.Err, "partition index -1 out of range [0, 2)")
+		}
+	})
+	t.Run("Out Of Range", func(t *testing.T) {
+		tb, ctx := tomb.WithContext(context.TODO())
+		source := tombstreams.NewChanSource(tb, generateCounter(ctx, 6))
+		flows := tombstreams.Partition(source, 2, parity)
+		flows[0].To(tombstreams.NewIgnoreSink(tb))
+		flows[1].To(tombstreams.NewIgnoreSink(tb))
+
+		<-tb.Dead()
+
+		assert.EqualError(t, tb.Err(), "partition index -1 out of range [0, 2)")
+	})
+}
+
+func TestZip(t *testing.T) {
+	t.Run("Zip", func(t *testing.T) {
+		tb, ctx := tomb.WithContext(context.TODO())
+		numbers := tombstreams.NewChanSource(tb, generateCounter(ctx, 3))
+		letters := tombstreams.NewChanSource(tb, tombstreams.GenerateIDs(ctx, []string{"a", "b", "c", "d"}))
+
+		out := make(chan interface{})
+		sink := tombstreams.NewChanSink(out)
+
+		tb.Go(func() error {
+			tombstreams.Zip(numbers, letters).To(sink)
+			return nil
+		})
+
+		actual := collect[interface{}](sink)
+		<-tb.Dead()
+
+		assert.Equal(t, nil, tb.Err())
+		assert.Equal(t, []interface{}{
+			[]interface{}{0, "a"},
+			[]interface{}{1, "b"},
+			[]interface{}{2, "c"},
+		}, actual)
+	})
+	t.Run("Zip With", func(t *testing.T) {
+		sum := func(elems []interface{}) (interface{}, error) {
+			total := 0
+			for _, e := range elems {
+				total += e.(int)
+			}
+			return total, nil
+		}
+
+		tb, ctx := tomb.WithContext(context.TODO())
+		a := tombstreams.NewChanSource(tb, generateCounter(ctx, 4))
+		b := tombstreams.NewChanSource(tb, generateCounter(ctx, 5)).Via(tombstreams.NewMap(tb, func(in interface{}) (interface{}, error) {
+			return in.(int) * 10, nil
+		}, 1, tombstreams.Ordered()))
+		c := tombstreams.NewChanSource(tb, generateCounter(ctx, 6))
+
+		out := make(chan interface{})
+		sink := tombstreams.NewChanSink(out)
+
+		tb.Go(func() error {
+			tombstreams.ZipWith(sum, a, b, c).To(sink)
+			return nil
+		})
+
+		actual := collect[interface{}](sink)
+		<-tb.Dead()
+
+		assert.Equal(t, nil, tb.Err())
+		assert.Equal(t, []interface{}{0, 12, 24, 36}, actual)
+	})
+	t.Run("Error", func(t *testing.T) {
+		fail := func(elems []interface{}) (interface{}, error) {
+			if elems[0] == 1 {
+				return nil, fmt.Errorf("error!")
+			}
+			return elems, nil
+		}
+
+		tb, ctx := tomb.WithContext(context.TODO())
+		a := tombstreams.NewChanSource(tb, generateCounter(ctx, 3))
+		b := tombstreams.NewChanSource(tb, generateCounter(ctx, 3))
+
+		tombstreams.ZipWith(fail, a, b).To(tombstreams.NewIgnoreSink(tb))
+
+		<-tb.Dead()
+
+		assert.EqualError(t, tb.Err(), "error!")
+	})
+}
+
+func TestConcat(t *testing.T) {
+	t.Run("Normal", func(t *testing.T) {
+		tb, ctx := tomb.WithContext(context.TODO())
+		backlog := tombstreams.NewChanSource(tb, tombstreams.GenerateIDs(ctx, []string{"a", "b", "c"}))
+		live := tombstreams.NewChanSource(tb, generateCounter(ctx, 3))
+		empty := tombstreams.NewChanSource(tb, tombstreams.GenerateIDs(ctx, nil))
+
+		out := make(chan interface{})
+		sink := tombstreams.NewChanSink(out)
+
+		tb.Go(func() error {
+			tombstreams.Concat(backlog, empty, live).To(sink)
+			return nil
+		})
+
+		actual := collect[interface{}](sink)
+		<-tb.Dead()
+
+		assert.Equal(t, nil, tb.Err())
+		assert.Equal(t, []interface{}{"a", "b", "c", 0, 1, 2}, actual)
+	})
+	t.Run("Killed", func(t *testing.T) {
+		tb := &tomb.Tomb{}
+		first := tombstreams.NewChanSource(tb, make(chan interface{}))
+		second := tombstreams.NewChanSource(tb, make(chan interface{}))
+
+		sink := tombstreams.NewIgnoreSink(tb)
+		tb.Go(func() error {
+			tombstreams.Concat(first, second).To(sink)
+			return nil
+		})
+		tb.Kill(fmt.Errorf("killed"))
+
+		<-tb.Dead()
+
+		assert.EqualError(t, tb.Err(), "killed")
+	})
+}
+
+func TestJoin(t *testing.T) {
+	key := func(elem interface{}) (interface{}, error) {
+		return strings.Split(elem.(string), ":")[0], nil
+	}
+	run := func(kind tombstreams.JoinKind, feed func(left, right chan interface{})) []interface{} {
+		tb := &tomb.Tomb{}
+		leftIn := make(chan interface{})
+		rightIn := make(chan interface{})
+		left := tombstreams.NewChanSource(tb, leftIn)
+		right := tombstreams.NewChanSource(tb, rightIn)
+
+		out := make(chan interface{})
+		sink := tombstreams.NewChanSink(out)
+		tb.Go(func() error {
+			tombstreams.Join(left, right, key, key, 100*time.Millisecond, kind).To(sink)
+			return nil
+		})
+		tb.Go(func() error {
+			defer close(leftIn)
+			defer close(rightIn)
+			feed(leftIn, rightIn)
+			return nil
+		})
+
+		actual := collect[interface{}](sink)
+		<-tb.Dead()
+		assert.Equal(t, nil, tb.Err())
+		return actual
+	}
+	feed := func(left, right chan interface{}) {
+		left <- "a:click"
+		right <- "a:view"
+		right <- "b:view"
+		// c arrives after the window of the previous elements.
+		time.Sleep(250 * time.Millisecond)
+		left <- "b:click"
+		left <- "c:click"
+		right <- "c:view"
+	}
+
+	t.Run("Inner", func(t *testing.T) {
+		actual := run(tombstreams.JoinInner, feed)
+		assert.Equal(t, []interface{}{
+			tombstreams.Pair{Left: "a:click", Right: "a:view"},
+			tombstreams.Pair{Left: "c:click", Right: "c:view"},
+		}, actual)
+	})
+	t.Run("Left Outer", func(t *testing.T) {
+		actual := run(tombstreams.JoinLeftOuter, feed)
+		assert.Equal(t, []interface{}{
+			tombstreams.Pair{Left: "a:click", Right: "a:view"},
+			tombstreams.Pair{Left: "c:click", Right: "c:view"},
+			tombstreams.Pair{Left: "b:click", Right: nil},
+		}, actual)
+	})
+	t.Run("Full Outer", func(t *testing.T) {
+		actual := run(tombstreams.JoinFullOuter, feed)
+		assert.Equal(t, []interface{}{
+			tombstreams.Pair{Left: "a:click", Right: "a:view"},
+			tombstreams.Pair{Left: nil, Right: "b:view"},
+			tombstreams.Pair{Left: "c:click", Right: "c:view"},
+			tombstreams.Pair{Left: "b:click", Right: nil},
+		}, actual)
+	})
+	t.Run("Multiple Matches", func(t *testing.T) {
+		actual := run(tombstreams.JoinInner, func(left, right chan interface{}) {
+			left <- "a:1"
+			left <- "a:2"
+			right <- "a:3"
+		})
+		assert.Equal(t, []interface{}{
+			tombstreams.Pair{Left: "a:1", Right: "a:3"},
+			tombstreams.Pair{Left: "a:2", Right: "a:3"},
+		}, actual)
+	})
+	t.Run("Key Error", func(t *testing.T) {
+		tb := &tomb.Tomb{}
+		in := make(chan interface{}, 1)
+		in <- "a"
+		failing := func(interface{}) (interface{}, error) {
+			return nil, fmt.Errorf("no key")
+		}
+		left := tombstreams.NewChanSource(tb, in)
+		right := tombstreams.NewChanSource(tb, make(chan interface{}))
+
+		sink := tombstreams.NewIgnoreSink(tb)
+		tb.Go(func() error {
+			tombstreams.Join(left, right, failing, key, time.Second, tombstreams.JoinInner).To(sink)
+			return nil
+		})
+
+		<-tb.Dead()
+
+		assert.EqualError(t, tb.Err(), "no key")
+	})
+	t.Run("Key Error Plain Outlet", func(t *testing.T) {
+		tb := &tomb.Tomb{}
+		in := make(chan interface{}, 1)
+		in <- "a"
+		failing := func(interface{}) (interface{}, error) {
+			return nil, fmt.Errorf("no key")
+		}
+		left := &plainOutlet{in, tb}
+		right := &plainOutlet{make(chan interface{}), tb}
+
+		sink := tombstreams.NewIgnoreSink(tb)
+		tb.Go(func() error {
+			tombstreams.Join(left, right, failing, key, time.Second, tombstreams.JoinInner).To(sink)
+			return nil
+		})
+
+		<-tb.Dead()
+
+		assert.EqualError(t, tb.Err(), "no key")
+	})
+}
+
+func TestScan(t *testing.T) {
+	sum := func(acc, elem interface{}) (interface{}, error) {
+		return acc.(int) + elem.(int), nil
+	}
+	run := func(count int, flow func(tb *tomb.Tomb) tombstreams.Flow) ([]interface{}, error) {
+		tb, ctx := tomb.WithContext(context.TODO())
+		source := tombstreams.NewChanSource(tb, generateCounter(ctx, count))
+		sink := tombstreams.NewChanSink(make(chan interface{}))
+
+		tb.Go(func() error {
+			source.Via(flow(tb)).To(sink)
+			return nil
+		})
+
+		actual := collect[interface{}](sink)
+		<-tb.Dead()
+		return actual, tb.Err()
+	}
+
+	t.Run("Scan", func(t *testing.T) {
+		actual, err := run(5, func(tb *tomb.Tomb) tombstreams.Flow {
+			return tombstreams.NewScan(tb, sum, 10)
+		})
+
+		assert.Equal(t, nil, err)
+		assert.Equal(t, []interface{}{10, 11, 13, 16, 20}, actual)
+	})
+	t.Run("Fold", func(t *testing.T) {
+		actual, err := run(5, func(tb *tomb.Tomb) tombstreams.Flow {
+			return tombstreams.NewFold(tb, sum, 10)
+		})
+
+		assert.Equal(t, nil, err)
+		assert.Equal(t, []interface{}{20}, actual)
+	})
+	t.Run("Fold Empty", func(t *testing.T) {
+		actual, err := run(0, func(tb *tomb.Tomb) tombstreams.Flow {
+			return tombstreams.NewFold(tb, sum, 10)
+		})
+
+		assert.Equal(t, nil, err)
+		assert.Equal(t, []interface{}{10}, actual)
+	})
+	t.Run("Reduce", func(t *testing.T) {
+		actual, err := run(5, func(tb *tomb.Tomb) tombstreams.Flow {
+			return tombstreams.NewReduce(tb, func(acc, elem interface{}) (interface{}, error) {
+				if elem.(int) > acc.(int) {
+					return elem, nil
+				}
+				return acc, nil
+			})
+		})
+
+		assert.Equal(t, nil, err)
+		assert.Equal(t, []interface{}{4}, actual)
+	})
+	t.Run("Reduce Empty", func(t *testing.T) {
+		actual, err := run(0, func(tb *tomb.Tomb) tombstreams.Flow {
+			return tombstreams.NewReduce(tb, sum)
+		})
+
+		assert.Equal(t, nil, err)
+		assert.Equal(t, []interface{}{}, actual)
+	})
+	t.Run("Error", func(t *testing.T) {
+		_, err := run(5, func(tb *tomb.Tomb) tombstreams.Flow {
+			return tombstreams.NewFold(tb, func(acc, elem interface{}) (interface{}, error) {
+				if elem.(int) == 3 {
+					return nil, fmt.Errorf("bad element %v", elem)
+				}
+				return sum(acc, elem)
+			}, 0)
+		})
+
+		assert.EqualError(t, err, "bad element 3")
+	})
+	t.Run("Error Resume", func(t *testing.T) {
+		actual, err := run(5, func(tb *tomb.Tomb) tombstreams.Flow {
+			return tombstreams.NewScan(tb, func(acc, elem interface{}) (interface{}, error) {
+				if elem.(int) == 3 {
+					return nil, fmt.Errorf("bad element %v", elem)
+				}
+				return sum(acc, elem)
+			}, 0, tombstreams.OnErrorResume())
+		})
+
+		assert.Equal(t, nil, err)
+		assert.Equal(t, []interface{}{0, 1, 3, 7}, actual)
+	})
+}
+
+func TestTake(t *testing.T) {
+	run := func(source tombstreams.Source, flows ...tombstreams.Flow) []interface{} {
+		sink := tombstreams.NewChanSink(make(chan interface{}))
+		tb := source.Tomb()
+		tb.Go(func() error {
+			flow := source.Via(flows[0])
+			for _, f := range flows[1:] {
+				flow = flow.Via(f)
+			}
+			flow.To(sink)
+			return nil
+		})
+
+		actual := collect[interface{}](sink)
+		<-tb.Dead()
+		return actual
+	}
+	double := func(elem interface{}) (interface{}, error) {
+		return elem.(int) * 2, nil
+	}
+	lessThan := func(n int) tombstreams.FilterFunc {
+		return func(elem interface{}) (bool, error) {
+			return elem.(int) < n, nil
+		}
+	}
+
+	t.Run("Take", func(t *testing.T) {
+		tb := &tomb.Tomb{}
+		source, stopped := produceUntilCancelled(tb)
+
+		actual := run(source, tombstreams.NewMap(tb, double, 2), tombstreams.NewTake(tb, 3))
+		<-stopped
+
+		assert.Equal(t, nil, tb.Err())
+		assert.Len(t, actual, 3)
+	})
+	t.Run("Take Ordered", func(t *testing.T) {
+		tb := &tomb.Tomb{}
+		source, stopped := produceUntilCancelled(tb)
+
+		actual := run(source,
+			tombstreams.NewMap(tb, double, 4, tombstreams.Ordered()),
+			tombstreams.NewTake(tb, 3),
+		)
+		<-stopped
+
+		assert.Equal(t, nil, tb.Err())
+		assert.Equal(t, []interface{}{0, 2, 4}, actual)
+	})
+	t.Run("Take Short Input", func(t *testing.T) {
+		tb, ctx := tomb.WithContext(context.TODO())
+		source := tombstreams.NewChanSource(tb, generateCounter(ctx, 2))
+
+		actual := run(source, tombstreams.NewTake(tb, 3))
+
+		assert.Equal(t, nil, tb.Err())
+		assert.Equal(t, []interface{}{0, 1}, actual)
+	})
+	t.Run("Take While", func(t *testing.T) {
+		tb := &tomb.Tomb{}
+		source, stopped := produceUntilCancelled(tb)
+
+		actual := run(source, tombstreams.NewTakeWhile(tb, lessThan(4)))
+		<-stopped
+
+		assert.Equal(t, nil, tb.Err())
+		assert.Equal(t, []interface{}{0, 1, 2, 3}, actual)
+	})
+	t.Run("Skip", func(t *testing.T) {
+		tb, ctx := tomb.WithContext(context.TODO())
+		source := tombstreams.NewChanSource(tb, generateCounter(ctx, 5))
+
+		actual := run(source, tombstreams.NewSkip(tb, 3))
+
+		assert.Equal(t, nil, tb.Err())
+		assert.Equal(t, []interface{}{3, 4}, actual)
+	})
+	t.Run("Skip While", func(t *testing.T) {
+		tb := &tomb.Tomb{}
+		source, stopped := produceUntilCancelled(tb)
+
+		actual := run(source, tombstreams.NewSkipWhile(tb, lessThan(3)), tombstreams.NewTake(tb, 2))
+		<-stopped
+
+		assert.Equal(t, nil, tb.Err())
+		assert.Equal(t, []interface{}{3, 4}, actual)
+	})
+	t.Run("Predicate Error", func(t *testing.T) {
+		tb := &tomb.Tomb{}
+		source, _ := produceUntilCancelled(tb)
+
+		run(source, tombstreams.NewTakeWhile(tb, func(elem interface{}) (bool, error) {
+			return false, fmt.Errorf("bad element %v", elem)
+		}))
+
+		assert.EqualError(t, tb.Err(), "bad element 0")
+	})
+}
+
+func TestCancel(t *testing.T) {
+	isKey := func(elem interface{}) (interface{}, error) {
+		return elem.(int) % 2, nil
+	}
+
+	t.Run("Merge", func(t *testing.T) {
+		tb := &tomb.Tomb{}
+		a, aStopped := produceUntilCancelled(tb)
+		b, bStopped := produceUntilCancelled(tb)
+		sink := tombstreams.NewChanSink(make(chan interface{}))
+
+		tb.Go(func() error {
+			merged := tombstreams.Merge(a.Via(tombstreams.NewPassThrough(tb)), b.Via(tombstreams.NewPassThrough(tb)))
+			merged.Via(tombstreams.NewTake(tb, 5)).To(sink)
+			return nil
+		})
+
+		actual := collect[interface{}](sink)
+		<-aStopped
+		<-bStopped
+		<-tb.Dead()
+
+		assert.Equal(t, nil, tb.Err())
+		assert.Len(t, actual, 5)
+	})
+	t.Run("FanOut", func(t *testing.T) {
+		tb := &tomb.Tomb{}
+		source, stopped := produceUntilCancelled(tb)
+		first := tombstreams.NewChanSink(make(chan interface{}))
+		second := tombstreams.NewChanSink(make(chan interface{}))
+
+		flows := tombstreams.FanOut(source, 2)
+		tb.Go(func() error {
+			flows[0].Via(tombstreams.NewTake(tb, 2)).To(first)
+			return nil
+		})
+		tb.Go(func() error {
+			flows[1].Via(tombstreams.NewTake(tb, 4)).To(second)
+			return nil
+		})
+
+		var firstActual []interface{}
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			firstActual = collect[interface{}](first)
+		}()
+		secondActual := collect[interface{}](second)
+		wg.Wait()
+		<-stopped
+		<-tb.Dead()
+
+		assert.Equal(t, nil, tb.Err())
+		assert.Equal(t, []interface{}{0, 1}, firstActual)
+		assert.Equal(t, []interface{}{0, 1, 2, 3}, secondActual)
+	})
+	t.Run("Balance", func(t *testing.T) {
+		for _, strategy := range []tombstreams.BalanceStrategy{tombstreams.BalanceRoundRobin, tombstreams.BalanceFirstReady} {
+			tb := &tomb.Tomb{}
+			source, stopped := produceUntilCancelled(tb)
+
+			var count int64
+			flows := tombstreams.Balance(source, 3, strategy)
+			for i, flow := range flows {
+				flow := flow.Via(tombstreams.NewTake(tb, uint(i+1)))
+				tb.Go(func() error {
+					for range flow.Out() {
+						atomic.AddInt64(&count, 1)
+					}
+					return nil
+				})
+			}
+
+			<-stopped
+			<-tb.Dead()
+
+			assert.Equal(t, nil, tb.Err())
+			assert.Equal(t, int64(6), atomic.LoadInt64(&count))
+		}
+	})
+	t.Run("Partition", func(t *testing.T) {
+		tb := &tomb.Tomb{}
+		source, stopped := produceUntilCancelled(tb)
+		sink := tombstreams.NewChanSink(make(chan interface{}))
+
+		flows := tombstreams.Partition(source, 2, func(elem interface{}) (int, error) {
+			return elem.(int) % 2, nil
+		})
+		tb.Go(func() error {
+			flows[0].Via(tombstreams.NewTake(tb, 0)).To(tombstreams.NewIgnoreSink(tb))
+			return nil
+		})
+		tb.Go(func() error {
+			flows[1].Via(tombstreams.NewTake(tb, 3)).To(sink)
+			return nil
+		})
+
+		actual := collect[interface{}](sink)
+		<-stopped
+		<-tb.Dead()
+
+		assert.Equal(t, nil, tb.Err())
+		assert.Equal(t, []interface{}{1, 3, 5}, actual)
+	})
+	t.Run("Concat", func(t *testing.T) {
+		tb, ctx := tomb.WithContext(context.TODO())
+		backlog := tombstreams.NewChanSource(tb, generateCounter(ctx, 2))
+		live, stopped := produceUntilCancelled(tb)
+		sink := tombstreams.NewChanSink(make(chan interface{}))
+
+		tb.Go(func() error {
+			tombstreams.Concat(backlog, live).Via(tombstreams.NewTake(tb, 4)).To(sink)
+			return nil
+		})
+
+		actual := collect[interface{}](sink)
+		<-stopped
+		<-tb.Dead()
+
+		assert.Equal(t, nil, tb.Err())
+		assert.Equal(t, []interface{}{0, 1, 0, 1}, actual)
+	})
+	t.Run("Zip", func(t *testing.T) {
+		tb := &tomb.Tomb{}
+		a, aStopped := produceUntilCancelled(tb)
+		b, bStopped := produceUntilCancelled(tb)
+		sink := tombstreams.NewChanSink(make(chan interface{}))
+
+		tb.Go(func() error {
+			tombstreams.Zip(a, b).Via(tombstreams.NewTake(tb, 2)).To(sink)
+			return nil
+		})
+
+		actual := collect[interface{}](sink)
+		<-aStopped
+		<-bStopped
+		<-tb.Dead()
+
+		assert.Equal(t, nil, tb.Err())
+		assert.Equal(t, []interface{}{[]interface{}{0, 0}, []interface{}{1, 1}}, actual)
+	})
+	t.Run("Join", func(t *testing.T) {
+		tb := &tomb.Tomb{}
+		a, aStopped := produceUntilCancelled(tb)
+		b, bStopped := produceUntilCancelled(tb)
+		sink := tombstreams.NewChanSink(make(chan interface{}))
+
+		tb.Go(func() error {
+			joined := tombstreams.Join(a, b, isKey, isKey, time.Hour, tombstreams.JoinInner)
+			joined.Via(tombstreams.NewTake(tb, 3)).To(sink)
+			return nil
+		})
+
+		actual := collect[interface{}](sink)
+		<-aStopped
+		<-bStopped
+		<-tb.Dead()
+
+		assert.Equal(t, nil, tb.Err())
+		assert.Len(t, actual, 3)
+	})
+	t.Run("GroupBy", func(t *testing.T) {
+		tb := &tomb.Tomb{}
+		source, stopped := produceUntilCancelled(tb)
+		sink := tombstreams.NewChanSink(make(chan interface{}))
+
+		groupBy := tombstreams.NewGroupBy(tb, isKey, func(key interface{}, sub tombstreams.Flow) tombstreams.Flow {
+			return sub.Via(tombstreams.NewMap(tb, func(elem interface{}) (interface{}, error) {
+				return elem, nil
+			}, 1))
+		}, 0, 0)
+		tb.Go(func() error {
+			source.Via(groupBy).Via(tombstreams.NewTake(tb, 4)).To(sink)
+			return nil
+		})
+
+		actual := collect[interface{}](sink)
+		<-stopped
+		<-tb.Dead()
+
+		assert.Equal(t, nil, tb.Err())
+		assert.Len(t, actual, 4)
+	})
+	t.Run("KeyedMap", func(t *testing.T) {
+		tb := &tomb.Tomb{}
+		source, stopped := produceUntilCancelled(tb)
+		sink := tombstreams.NewChanSink(make(chan interface{}))
+
+		keyedMap := tombstreams.NewKeyedMap(tb, isKey, func(elem interface{}) (interface{}, error) {
+			return elem, nil
+		}, 2)
+		tb.Go(func() error {
+			source.Via(keyedMap).Via(tombstreams.NewTake(tb, 4)).To(sink)
+			return nil
+		})
+
+		actual := collect[interface{}](sink)
+		<-stopped
+		<-tb.Dead()
+
+		assert.Equal(t, nil, tb.Err())
+		assert.Len(t, actual, 4)
+	})
+	t.Run("Throttle Backpressure", func(t *testing.T) {
+		tb := &tomb.Tomb{}
+		source, stopped := produceUntilCancelled(tb)
+		sink := tombstreams.NewChanSink(make(chan interface{}))
+
+		throttle := tombstreams.NewThrottle(tb, 1, time.Hour, 1, tombstreams.ThrottleBackpressure)
+		tb.Go(func() error {
+			source.Via(throttle).Via(tombstreams.NewTake(tb, 1)).To(sink)
+			return nil
+		})
+
+		actual := collect[interface{}](sink)
+		<-stopped
+		select {
+		case <-tb.Dead():
+		case <-time.After(time.Second):
+			t.Fatal("throttle wait ignored the cancellation")
+		}
+
+		assert.Equal(t, nil, tb.Err())
+		assert.Equal(t, []interface{}{0}, actual)
+	})
+	t.Run("Retry Backoff", func(t *testing.T) {
+		tb := &tomb.Tomb{}
+		source, stopped := produceUntilCancelled(tb)
+		sink := tombstreams.NewChanSink(make(chan interface{}))
+
+		failAfterFirst := func(elem interface{}) (interface{}, error) {
+			if elem.(int) > 0 {
+				return nil, fmt.Errorf("flaky")
+			}
+			return elem, nil
+		}
+		policy := tombstreams.RetryPolicy{MaxAttempts: 10, Backoff: time.Hour}
+		mapper := tombstreams.NewMap(tb, failAfterFirst, 1, tombstreams.OnErrorRetry(policy))
+		tb.Go(func() error {
+			source.Via(mapper).Via(tombstreams.NewTake(tb, 1)).To(sink)
+			return nil
+		})
+
+		actual := collect[interface{}](sink)
+		<-stopped
+		select {
+		case <-tb.Dead():
+		case <-time.After(time.Second):
+			t.Fatal("retry backoff ignored the cancellation")
+		}
+
+		assert.Equal(t, nil, tb.Err())
+		assert.Equal(t, []interface{}{0}, actual)
+	})
+}
+
+func TestPipelineRunner(t *testing.T) {
+	double := func(elem interface{}) (interface{}, error) {
+		if elem.(int) == 3 {
+			return nil, fmt.Errorf("bad element %v", elem)
+		}
+		return elem.(int) * 2, nil
+	}
+	even := func(elem interface{}) (bool, error) {
+		return elem.(int)%4 == 0, nil
+	}
+
+	t.Run("Normal", func(t *testing.T) {
+		sink := tombstreams.NewChanSink(make(chan interface{}))
+		p := tombstreams.NewPipeline(func(p *tombstreams.Pipeline) error {
+			tb := p.Tomb()
+			source := p.Source(tombstreams.NewChanSource(tb, generateCounter(p.Context(), 5)))
+			source.
+				Via(p.Stage(tombstreams.NewMap(tb, double, 1, tombstreams.WithName("double"), tombstreams.OnErrorResume()))).
+				Via(p.Stage(tombstreams.NewFilter(tb, even, 1))).
+				To(sink)
+			return nil
+		})
+		p.Run(context.TODO())
+
+		actual := collect[interface{}](sink)
+
+		assert.Equal(t, nil, p.Wait())
+		assert.Equal(t, []interface{}{0, 4, 8}, actual)
+		assert.Equal(t, []tombstreams.Stats{
+			{Stage: "double", Processed: 4, Failed: 1},
+			{Stage: "Filter", Processed: 3},
+		}, p.Summary())
+	})
+	t.Run("Error", func(t *testing.T) {
+		p := tombstreams.NewPipeline(func(p *tombstreams.Pipeline) error {
+			tb := p.Tomb()
+			source := p.Source(tombstreams.NewChanSource(tb, generateCounter(p.Context(), 5)))
+			source.Via(p.Stage(tombstreams.NewMap(tb, double, 1))).To(tombstreams.NewIgnoreSink(tb))
+			return nil
+		})
+		p.Run(context.TODO())
+
+		assert.EqualError(t, p.Wait(), "bad element 3")
+		assert.Equal(t, uint64(1), p.Summary()[0].Failed)
+	})
+	t.Run("Build Error", func(t *testing.T) {
+		p := tombstreams.NewPipeline(func(p *tombstreams.Pipeline) error {
+			return fmt.Errorf("no source")
+		})
+		p.Run(context.TODO())
+
+		assert.EqualError(t, p.Wait(), "no source")
+	})
+	t.Run("Stop", func(t *testing.T) {
+		sink := tombstreams.NewChanSink(make(chan interface{}))
+		p := tombstreams.NewPipeline(func(p *tombstreams.Pipeline) error {
+			tb := p.Tomb()
+			source, _ := produceUntilCancelled(tb)
+			p.Source(source).Via(p.Stage(tombstreams.NewPassThrough(tb))).To(sink)
+			return nil
+		})
+		p.Run(context.TODO())
+
+		actual := []interface{}{<-sink.Out, <-sink.Out, <-sink.Out}
+		p.Stop()
+		actual = append(actual, collect[interface{}](sink)...)
+
+		assert.Equal(t, nil, p.Wait())
+		// Elements read before Stop still reach the sink.
+		assert.GreaterOrEqual(t, len(actual), 3)
+		for i, e := range actual {
+			assert.Equal(t, i, e)
+		}
+		assert.Equal(t, uint64(len(actual)), p.Summary()[0].Processed)
+	})
+	t.Run("Kill", func(t *testing.T) {
+		p := tombstreams.NewPipeline(func(p *tombstreams.Pipeline) error {
+			tb := p.Tomb()
+			source, _ := produceUntilCancelled(tb)
+			p.Source(source).Via(tombstreams.NewPassThrough(tb)).To(tombstreams.NewIgnoreSink(tb))
+			return nil
+		})
+		p.Run(context.TODO())
+		p.Kill(fmt.Errorf("killed"))
+
+		assert.EqualError(t, p.Wait(), "killed")
+		assert.Equal(t, context.Canceled, p.Context().Err())
+	})
+	t.Run("Not Running", func(t *testing.T) {
+		built := false
+		p := tombstreams.NewPipeline(func(p *tombstreams.Pipeline) error {
+			built = true
+			return nil
+		})
+
+		assert.Nil(t, p.Tomb())
+		assert.Equal(t, tombstreams.ErrNotRunning, p.Wait())
+		assert.Equal(t, tombstreams.ErrNotRunning, p.Drain(time.Second))
+		assert.Equal(t, tombstreams.ErrNotRunning, p.HandleSignals(time.Second))
+
+		p.Kill(fmt.Errorf("killed"))
+		p.Run(context.TODO())
+
+		assert.EqualError(t, p.Wait(), "killed")
+		assert.False(t, built)
+	})
+	t.Run("Unconnected Source", func(t *testing.T) {
+		tb := &tomb.Tomb{}
+		in := make(chan interface{}, 1)
+		in <- 1
+		tombstreams.NewChanSource(tb, in)
+		tb.Go(func() error {
+			return nil
+		})
+
+		<-tb.Dead()
+
+		assert.Equal(t, nil, tb.Err())
+		assert.Len(t, in, 1)
+	})
+}
+
+func TestDrain(t *testing.T) {
+	t.Run("Normal", func(t *testing.T) {
+		sink := tombstreams.NewChanSink(make(chan interface{}))
+		p := tombstreams.NewPipeline(func(p *tombstreams.Pipeline) error {
+			tb := p.Tomb()
+			source, _ := produceUntilCancelled(tb)
+			slow := tombstreams.NewMap(tb, func(elem interface{}) (interface{}, error) {
+				time.Sleep(5 * time.Millisecond)
+				return elem, nil
+			}, 2, tombstreams.Ordered(), tombstreams.WithBuffer(4))
+			p.Source(source).Via(p.Stage(slow)).To(sink)
+			return nil
+		})
+		p.Run(context.TODO())
+
+		actual := make([]interface{}, 5)
+		for i := range actual {
+			actual[i] = <-sink.Out
+		}
+		done := make(chan error)
+		go func() {
+			done <- p.Drain(time.Second)
+		}()
+		actual = append(actual, collect[interface{}](sink)...)
+
+		assert.Equal(t, nil, <-done)
+		// Every element read by the source reached the sink, in order.
+		assert.GreaterOrEqual(t, len(actual), 5)
+		for i, e := range actual {
+			assert.Equal(t, i, e)
+		}
+	})
+	t.Run("Timeout", func(t *testing.T) {
+		stuck := make(chan struct{})
+		p := tombstreams.NewPipeline(func(p *tombstreams.Pipeline) error {
+			tb := p.Tomb()
+			source, _ := produceUntilCancelled(tb)
+			var once sync.Once
+			mapper := tombstreams.NewMap(tb, func(elem interface{}) (interface{}, error) {
+				once.Do(func() { close(stuck) })
+				<-tb.Dying()
+				return elem, nil
+			}, 1)
+			p.Source(source).Via(mapper).To(tombstreams.NewIgnoreSink(tb))
+			return nil
+		})
+		p.Run(context.TODO())
+		<-stuck
+
+		start := time.Now()
+		err := p.Drain(50 * time.Millisecond)
+
+		assert.Equal(t, tombstreams.ErrDrainTimeout, err)
+		assert.Less(t, int64(time.Since(start)), int64(time.Second))
+	})
+}
+
+func TestHandleSignals(t *testing.T) {
+	interrupt := func(t *testing.T) {
+		proc, err := os.FindProcess(os.Getpid())
+		assert.NoError(t, err)
+		assert.NoError(t, proc.Signal(os.Interrupt))
+	}
+	// run starts a pipeline whose stage closes reading at its first element,
+	// and then blocks until the tomb is dying if stuck is set.
+	run := func(stuck bool) (*tombstreams.Pipeline, chan struct{}) {
+		reading := make(chan struct{})
+		p := tombstreams.NewPipeline(func(p *tombstreams.Pipeline) error {
+			tb := p.Tomb()
+			source, _ := produceUntilCancelled(tb)
+			var once sync.Once
+			mapper := tombstreams.NewMap(tb, func(elem interface{}) (interface{}, error) {
+				once.Do(func() { close(reading) })
+				if stuck {
+					<-tb.Dying()
+				}
+				return elem, nil
+			}, 1)
+			p.Source(source).Via(mapper).To(tombstreams.NewIgnoreSink(tb))
+			return nil
+		})
+		p.Run(context.TODO())
+		return p, reading
+	}
+
+	t.Run("Graceful", func(t *testing.T) {
+		p, reading := run(false)
+		p.HandleSignals(time.Second, os.Interrupt)
+		<-reading
+		interrupt(t)
+
+		err := p.Wait()
+
+		assert.True(t, errors.Is(err, tombstreams.ErrInterrupted))
+		assert.Equal(t, &tombstreams.InterruptedError{Signal: os.Interrupt}, err)
+		assert.NoError(t, p.Tomb().Err())
+	})
+	t.Run("Forced", func(t *testing.T) {
+		p, reading := run(true)
+		p.HandleSignals(0, os.Interrupt)
+		<-reading
+		interrupt(t)
+		time.Sleep(20 * time.Millisecond)
+		interrupt(t)
+
+		err := p.Wait()
+
+		assert.True(t, errors.Is(err, tombstreams.ErrInterrupted))
+		assert.Equal(t, &tombstreams.InterruptedError{Signal: os.Interrupt, Forced: true}, err)
+	})
+	t.Run("Deadline", func(t *testing.T) {
+		p, reading := run(true)
+		p.HandleSignals(50*time.Millisecond, os.Interrupt)
+		<-reading
+		interrupt(t)
+
+		err := p.Wait()
+
+		assert.Equal(t, &tombstreams.InterruptedError{Signal: os.Interrupt, Forced: true}, err)
+	})
+	t.Run("Completed", func(t *testing.T) {
+		p := tombstreams.NewPipeline(func(p *tombstreams.Pipeline) error {
+			tb := p.Tomb()
+			source := tombstreams.NewChanSource(tb, generateCounter(p.Context(), 3))
+			source.Via(tombstreams.NewPassThrough(tb)).To(tombstreams.NewIgnoreSink(tb))
+			return nil
+		})
+		p.Run(context.TODO())
+		p.HandleSignals(time.Second, os.Interrupt)
+
+		assert.Equal(t, nil, p.Wait())
+	})
+}
+
+func TestSink(t *testing.T) {
+	t.Run("Ignore", func(t *testing.T) {
+		tb, ctx := tomb.WithContext(context.TODO())
+		source := tombstreams.NewChanSource(tb, generateCounter(ctx, 3))
+		sink := tombstreams.NewIgnoreSink(tb)
+
+		assert.Equal(t, nil, sink.Err())
+		tb.Go(func() error {
+			source.Via(tombstreams.NewPassThrough(tb)).To(sink)
+			return nil
+		})
+		<-sink.Done()
+
+		assert.Equal(t, nil, sink.Err())
+		<-tb.Dead()
+	})
+	t.Run("Killed", func(t *testing.T) {
+		tb := &tomb.Tomb{}
+		source := tombstreams.NewChanSource(tb, make(chan interface{}))
+		sink := tombstreams.NewStdoutSink(tb)
+
+		tb.Go(func() error {
+			source.Via(tombstreams.NewPassThrough(tb)).To(sink)
+			return nil
+		})
+		tb.Kill(fmt.Errorf("killed"))
+		<-sink.Done()
+
+		assert.EqualError(t, sink.Err(), "killed")
+		<-tb.Dead()
+	})
+	t.Run("Killed Without Error", func(t *testing.T) {
+		tb := &tomb.Tomb{}
+		sink := tombstreams.NewIgnoreSink(tb)
+
+		tb.Kill(nil)
+		<-sink.Done()
+
+		assert.Equal(t, tomb.ErrDying, sink.Err())
+		<-tb.Dead()
+	})
+	t.Run("Chan", func(t *testing.T) {
+		tb, ctx := tomb.WithContext(context.TODO())
+		source := tombstreams.NewChanSource(tb, generateCounter(ctx, 3))
+		sink := tombstreams.NewChanSink(make(chan interface{}, 3))
+
+		tb.Go(func() error {
+			source.Via(tombstreams.NewPassThrough(tb)).To(sink)
+			return nil
+		})
+		<-sink.Done()
+
+		actual := collect[interface{}](sink)
+
+		assert.Equal(t, nil, sink.Err())
+		assert.Equal(t, []interface{}{0, 1, 2}, actual)
+		<-tb.Dead()
+	})
+	t.Run("Chan Killed", func(t *testing.T) {
+		tb := &tomb.Tomb{}
+		source, stopped := produceUntilCancelled(tb)
+		sink := tombstreams.NewTombChanSink(tb, make(chan interface{}))
+
+		tb.Go(func() error {
+			source.To(sink)
+			return nil
+		})
+		// The consumer stops reading, the sink is blocked on Out.
+		<-sink.Out
+		tb.Kill(fmt.Errorf("killed"))
+		<-sink.Done()
+		<-stopped
+
+		assert.EqualError(t, sink.Err(), "killed")
+		<-tb.Dead()
+	})
+	t.Run("Chan Literal", func(t *testing.T) {
+		tb, ctx := tomb.WithContext(context.TODO())
+		source := tombstreams.NewChanSource(tb, generateCounter(ctx, 3))
+		sink := &tombstreams.ChanSink{Out: make(chan interface{})}
+
+		tb.Go(func() error {
+			source.To(sink)
+			return nil
+		})
+
+		assert.Equal(t, []interface{}{0, 1, 2}, collect[interface{}](sink))
+		<-sink.Done()
+		assert.Equal(t, nil, sink.Err())
+		<-tb.Dead()
+	})
+}
+
+func TestStream(t *testing.T) {
+	square := func(elem interface{}) (interface{}, error) {
+		return elem.(int) * elem.(int), nil
+	}
+	odd := func(elem interface{}) (bool, error) {
+		return elem.(int)%2 == 1, nil
+	}
+	sum := func(acc, elem interface{}) (interface{}, error) {
+		return acc.(int) + elem.(int), nil
+	}
+
+	t.Run("Source To Sink", func(t *testing.T) {
+		tb, ctx := tomb.WithContext(context.TODO())
+		sink := tombstreams.NewChanSink(make(chan interface{}))
+
+		tb.Go(func() error {
+			tombstreams.NewChanSource(tb, generateCounter(ctx, 3)).To(sink)
+			return nil
+		})
+
+		actual := collect[interface{}](sink)
+		<-tb.Dead()
+
+		assert.Equal(t, nil, tb.Err())
+		assert.Equal(t, []interface{}{0, 1, 2}, actual)
+	})
+	t.Run("Chain", func(t *testing.T) {
+		tb, ctx := tomb.WithContext(context.TODO())
+		sink := tombstreams.NewChanSink(make(chan interface{}))
+
+		tb.Go(func() error {
+			tombstreams.NewChanSource(tb, generateCounter(ctx, 10)).
+				Skip(1).
+				Filter(odd, 1).
+				Map(square, 2, tombstreams.Ordered()).
+				Scan(sum, 0).
+				To(sink)
+			return nil
+		})
+
+		actual := collect[interface{}](sink)
+		<-tb.Dead()
+
+		assert.Equal(t, nil, tb.Err())
+		assert.Equal(t, []interface{}{1, 10, 35, 84, 165}, actual)
+	})
+	t.Run("Take", func(t *testing.T) {
+		tb := &tomb.Tomb{}
+		source, stopped := produceUntilCancelled(tb)
+		sink := tombstreams.NewChanSink(make(chan interface{}))
+
+		tb.Go(func() error {
+			source.Filter(odd, 1).Take(3).To(sink)
+			return nil
+		})
+
+		actual := collect[interface{}](sink)
+		<-stopped
+		<-tb.Dead()
+
+		assert.Equal(t, nil, tb.Err())
+		assert.Equal(t, []interface{}{1, 3, 5}, actual)
+	})
+	t.Run("Through", func(t *testing.T) {
+		tb, ctx := tomb.WithContext(context.TODO())
+		sink := tombstreams.NewChanSink(make(chan interface{}))
+		flow := tombstreams.Merge(tombstreams.NewChanSource(tb, generateCounter(ctx, 4)).Via(tombstreams.NewPassThrough(tb)))
+
+		tb.Go(func() error {
+			tombstreams.NewStream(flow).Batch(2, time.Hour).Through(tombstreams.NewPassThrough(tb)).To(sink)
+			return nil
+		})
+
+		actual := collect[interface{}](sink)
+		<-tb.Dead()
+
+		assert.Equal(t, nil, tb.Err())
+		assert.Equal(t, []interface{}{[]interface{}{0, 1}, []interface{}{2, 3}}, actual)
+	})
+	t.Run("Pipeline Source", func(t *testing.T) {
+		sink := tombstreams.NewChanSink(make(chan interface{}))
+		p := tombstreams.NewPipeline(func(p *tombstreams.Pipeline) error {
+			tb := p.Tomb()
+			source, _ := produceUntilCancelled(tb)
+			stream := p.Source(source.Filter(odd, 1))
+			tombstreams.Merge(stream.Via(tombstreams.NewPassThrough(tb))).To(sink)
+			return nil
+		})
+		p.Run(context.TODO())
+
+		actual := []interface{}{<-sink.Out, <-sink.Out, <-sink.Out}
+		p.Stop()
+		actual = append(actual, collect[interface{}](sink)...)
+
+		assert.Equal(t, nil, p.Wait())
+		// Stopping the stream stops its ChanSource, the elements read still arrive.
+		assert.GreaterOrEqual(t, len(actual), 3)
+		assert.Equal(t, []interface{}{1, 3, 5}, actual[:3])
+	})
+}
@@ -0,0 +1,111 @@
+package typed
+
+import (
+	"sync"
+
+	"gopkg.in/tomb.v2"
+)
+
+// FilterFunc is a filter predicate function.
+type FilterFunc[T any] func(T) (bool, error)
+
+// Filter filters the incoming elements using a predicate.
+// If the predicate returns true the element is passed downstream,
+// if it returns false the element is discarded.
+//
+// in  -- 1 -- 2 ---- 3 -- 4 ------ 5 --
+//        |    |      |    |        |
+//    [---------- FilterFunc -----------]
+//        |    |                    |
+// out -- 1 -- 2 ------------------ 5 --
+type Filter[T any] struct {
+	FilterF     FilterFunc[T]
+	in          chan T
+	out         chan T
+	parallelism uint
+	t           *tomb.Tomb
+}
+
+// Verify Filter satisfies the Flow interface.
+var _ Flow[int, int] = (*Filter[int])(nil)
+
+// NewFilter returns a new Filter instance.
+// filterFunc is the filter predicate function.
+// parallelism is the flow parallelism factor. In case the events order matters, use parallelism = 1.
+func NewFilter[T any](t *tomb.Tomb, filterFunc FilterFunc[T], parallelism uint) *Filter[T] {
+	filter := &Filter[T]{
+		filterFunc,
+		make(chan T),
+		make(chan T),
+		parallelism,
+		t,
+	}
+	if t.Alive() {
+		t.Go(filter.doStream)
+	}
+	return filter
+}
+
+// To streams data to the given sink
+func (f *Filter[T]) To(sink Sink[T]) {
+	transmit[T](f, sink)
+}
+
+// Out returns an output channel for sending data
+func (f *Filter[T]) Out() <-chan T {
+	return f.out
+}
+
+// In returns an input channel for receiving data
+func (f *Filter[T]) In() chan<- T {
+	return f.in
+}
+
+func (f *Filter[T]) Tomb() *tomb.Tomb {
+	return f.t
+}
+
+// throws items that are not satisfying the filter function
+func (f *Filter[T]) doStream() error {
+	defer close(f.out)
+
+	var wg sync.WaitGroup
+	for i := 0; i < int(f.parallelism); i++ {
+		if !f.t.Alive() {
+			break
+		}
+		wg.Add(1)
+		f.t.Go(func() error {
+			defer wg.Done()
+			for {
+				var include bool
+				var err error
+				var e T
+				select {
+				case elem, ok := <-f.in:
+					if ok {
+						include, err = f.FilterF(elem)
+						if err != nil {
+							return err
+						}
+						e = elem
+					} else {
+						return nil
+					}
+				case <-f.t.Dying():
+					return nil
+				}
+				if include {
+					select {
+					case f.out <- e:
+					case <-f.t.Dying():
+						return nil
+					}
+				}
+			}
+		})
+	}
+
+	wg.Wait()
+	return nil
+}
@@ -0,0 +1,106 @@
+package typed
+
+import (
+	"sync"
+
+	"gopkg.in/tomb.v2"
+)
+
+// FlatMapFunc is a FlatMap transformation function.
+type FlatMapFunc[In, Out any] func(In) ([]Out, error)
+
+// FlatMap takes one element and produces zero, one, or more elements.
+//
+// in  -- 1 -- 2 ---- 3 -- 4 ------ 5 --
+//        |    |      |    |        |
+//    [---------- FlatMapFunc ----------]
+//        |    |           |   |    |
+// out -- 1' - 2' -------- 4'- 4''- 5' -
+type FlatMap[In, Out any] struct {
+	FlatMapF    FlatMapFunc[In, Out]
+	in          chan In
+	out         chan Out
+	parallelism uint
+	t           *tomb.Tomb
+}
+
+// Verify FlatMap satisfies the Flow interface.
+var _ Flow[int, string] = (*FlatMap[int, string])(nil)
+
+// NewFlatMap returns a new FlatMap instance.
+// flatMapFunc is the FlatMap transformation function.
+// parallelism is the flow parallelism factor. In case the events order matters, use parallelism = 1.
+func NewFlatMap[In, Out any](t *tomb.Tomb, flatMapFunc FlatMapFunc[In, Out], parallelism uint) *FlatMap[In, Out] {
+	flatMap := &FlatMap[In, Out]{
+		flatMapFunc,
+		make(chan In),
+		make(chan Out),
+		parallelism,
+		t,
+	}
+	if t.Alive() {
+		t.Go(flatMap.doStream)
+	}
+	return flatMap
+}
+
+// To streams data to the given sink
+func (fm *FlatMap[In, Out]) To(sink Sink[Out]) {
+	transmit[Out](fm, sink)
+}
+
+// Out returns an output channel for sending data
+func (fm *FlatMap[In, Out]) Out() <-chan Out {
+	return fm.out
+}
+
+// In returns an input channel for receiving data
+func (fm *FlatMap[In, Out]) In() chan<- In {
+	return fm.in
+}
+
+func (fm *FlatMap[In, Out]) Tomb() *tomb.Tomb {
+	return fm.t
+}
+
+func (fm *FlatMap[In, Out]) doStream() error {
+	defer close(fm.out)
+
+	var wg sync.WaitGroup
+	for i := 0; i < int(fm.parallelism); i++ {
+		if !fm.t.Alive() {
+			break
+		}
+		wg.Add(1)
+		fm.t.Go(func() error {
+			defer wg.Done()
+			for {
+				var trans []Out
+				var err error
+				select {
+				case elem, ok := <-fm.in:
+					if ok {
+						trans, err = fm.FlatMapF(elem)
+						if err != nil {
+							return err
+						}
+					} else {
+						return nil
+					}
+				case <-fm.t.Dying():
+					return nil
+				}
+				for _, item := range trans {
+					select {
+					case fm.out <- item:
+					case <-fm.t.Dying():
+						return nil
+					}
+				}
+			}
+		})
+	}
+
+	wg.Wait()
+	return nil
+}
@@ -0,0 +1,104 @@
+package typed
+
+import (
+	"sync"
+
+	"gopkg.in/tomb.v2"
+)
+
+// MapFunc is a Map transformation function.
+type MapFunc[In, Out any] func(In) (Out, error)
+
+// Map takes one element and produces one element.
+//
+// in  -- 1 -- 2 ---- 3 -- 4 ------ 5 --
+//        |    |      |    |        |
+//    [----------- MapFunc -------------]
+//        |    |      |    |        |
+// out -- 1' - 2' --- 3' - 4' ----- 5' -
+type Map[In, Out any] struct {
+	MapF        MapFunc[In, Out]
+	in          chan In
+	out         chan Out
+	parallelism uint
+	t           *tomb.Tomb
+}
+
+// Verify Map satisfies the Flow interface.
+var _ Flow[int, string] = (*Map[int, string])(nil)
+
+// NewMap returns a new Map instance.
+// mapFunc is the Map transformation function.
+// parallelism is the flow parallelism factor. In case the events order matters, use parallelism = 1.
+func NewMap[In, Out any](t *tomb.Tomb, mapFunc MapFunc[In, Out], parallelism uint) *Map[In, Out] {
+	_map := &Map[In, Out]{
+		mapFunc,
+		make(chan In),
+		make(chan Out),
+		parallelism,
+		t,
+	}
+	if t.Alive() {
+		t.Go(_map.doStream)
+	}
+	return _map
+}
+
+// To streams data to the given sink
+func (m *Map[In, Out]) To(sink Sink[Out]) {
+	transmit[Out](m, sink)
+}
+
+// Out returns an output channel for sending data
+func (m *Map[In, Out]) Out() <-chan Out {
+	return m.out
+}
+
+// In returns an input channel for receiving data
+func (m *Map[In, Out]) In() chan<- In {
+	return m.in
+}
+
+func (m *Map[In, Out]) Tomb() *tomb.Tomb {
+	return m.t
+}
+
+func (m *Map[In, Out]) doStream() error {
+	defer close(m.out)
+
+	var wg sync.WaitGroup
+	for i := 0; i < int(m.parallelism); i++ {
+		if !m.t.Alive() {
+			break
+		}
+		wg.Add(1)
+		m.t.Go(func() error {
+			defer wg.Done()
+			for {
+				var trans Out
+				var err error
+				select {
+				case elem, ok := <-m.in:
+					if ok {
+						trans, err = m.MapF(elem)
+						if err != nil {
+							return err
+						}
+					} else {
+						return nil
+					}
+				case <-m.t.Dying():
+					return nil
+				}
+				select {
+				case m.out <- trans:
+				case <-m.t.Dying():
+					return nil
+				}
+			}
+		})
+	}
+
+	wg.Wait()
+	return nil
+}
@@ -0,0 +1,71 @@
+package typed
+
+import "gopkg.in/tomb.v2"
+
+// PassThrough produces the received element as is.
+//
+// in  -- 1 -- 2 ---- 3 -- 4 ------ 5 --
+//        |    |      |    |        |
+// out -- 1 -- 2 ---- 3 -- 4 ------ 5 --
+type PassThrough[T any] struct {
+	in  chan T
+	out chan T
+	t   *tomb.Tomb
+}
+
+// Verify PassThrough satisfies the Flow interface.
+var _ Flow[int, int] = (*PassThrough[int])(nil)
+
+// NewPassThrough returns a new PassThrough instance.
+func NewPassThrough[T any](t *tomb.Tomb) *PassThrough[T] {
+	passThrough := &PassThrough[T]{
+		make(chan T),
+		make(chan T),
+		t,
+	}
+	if t.Alive() {
+		t.Go(passThrough.doStream)
+	}
+	return passThrough
+}
+
+// To streams data to the given sink
+func (pt *PassThrough[T]) To(sink Sink[T]) {
+	transmit[T](pt, sink)
+}
+
+// Out returns an output channel for sending data
+func (pt *PassThrough[T]) Out() <-chan T {
+	return pt.out
+}
+
+// In returns an input channel for receiving data
+func (pt *PassThrough[T]) In() chan<- T {
+	return pt.in
+}
+
+func (pt *PassThrough[T]) Tomb() *tomb.Tomb {
+	return pt.t
+}
+
+func (pt *PassThrough[T]) doStream() error {
+	defer close(pt.out)
+	for {
+		var e T
+		select {
+		case elem, ok := <-pt.in:
+			if ok {
+				e = elem
+			} else {
+				return nil
+			}
+		case <-pt.t.Dying():
+			return nil
+		}
+		select {
+		case pt.out <- e:
+		case <-pt.t.Dying():
+			return nil
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package typed
+
+import (
+	"fmt"
+
+	"gopkg.in/tomb.v2"
+)
+
+// ChanSink sends data to the output channel
+type ChanSink[T any] struct {
+	Out chan T
+}
+
+// NewChanSink returns a new ChanSink instance
+func NewChanSink[T any](out chan T) *ChanSink[T] {
+	return &ChanSink[T]{out}
+}
+
+// In returns an input channel for receiving data
+func (ch *ChanSink[T]) In() chan<- T {
+	return ch.Out
+}
+
+// FuncSink calls a function for every received element.
+// An error returned by the function kills the tomb.
+type FuncSink[T any] struct {
+	in chan T
+}
+
+// NewFuncSink returns a new FuncSink instance
+func NewFuncSink[T any](t *tomb.Tomb, sinkFunc func(T) error) *FuncSink[T] {
+	sink := &FuncSink[T]{make(chan T)}
+	if t.Alive() {
+		t.Go(func() error {
+			for {
+				select {
+				case elem, ok := <-sink.in:
+					if !ok {
+						return nil
+					}
+					if err := sinkFunc(elem); err != nil {
+						return err
+					}
+				case <-t.Dying():
+					return nil
+				}
+			}
+		})
+	}
+	return sink
+}
+
+// In returns an input channel for receiving data
+func (fs *FuncSink[T]) In() chan<- T {
+	return fs.in
+}
+
+// NewStdoutSink returns a new sink that prints items to stdout
+func NewStdoutSink[T any](t *tomb.Tomb) *FuncSink[T] {
+	return NewFuncSink(t, func(elem T) error {
+		fmt.Println(elem)
+		return nil
+	})
+}
@@ -0,0 +1,34 @@
+package typed
+
+import (
+	"gopkg.in/tomb.v2"
+)
+
+// ChanSource streams data from the input channel
+type ChanSource[T any] struct {
+	in <-chan T
+	t  *tomb.Tomb
+}
+
+// Verify ChanSource satisfies the Source interface.
+var _ Source[int] = (*ChanSource[int])(nil)
+
+// NewChanSource returns a new ChanSource instance
+func NewChanSource[T any](t *tomb.Tomb, in <-chan T) *ChanSource[T] {
+	return &ChanSource[T]{in, t}
+}
+
+// To streams data to the given sink
+func (cs *ChanSource[T]) To(sink Sink[T]) {
+	transmit[T](cs, sink)
+}
+
+// Out returns an output channel for sending data
+func (cs *ChanSource[T]) Out() <-chan T {
+	return cs.in
+}
+
+// Tomb returns the tomb context
+func (cs *ChanSource[T]) Tomb() *tomb.Tomb {
+	return cs.t
+}
@@ -0,0 +1,194 @@
+// Package typed is a generic, type-safe counterpart of the tombstreams API.
+//
+// Stages move values of a concrete type instead of interface{}, so stage
+// functions and sinks need no type assertions. They share the tomb-based
+// cancellation of the tombstreams package and can be connected to it with
+// Typed and Untyped.
+package typed
+
+import (
+	"fmt"
+
+	"gopkg.in/tomb.v2"
+
+	"github.com/artificial-james/tombstreams"
+)
+
+// Inlet is a type that exposes one open input of type T.
+// Implemented by the Flow and Sink.
+type Inlet[T any] interface {
+	In() chan<- T
+}
+
+// Outlet is a type that exposes one open output of type T.
+// Implemented by the Source and Flow.
+type Outlet[T any] interface {
+	Out() <-chan T
+	Tomb() *tomb.Tomb
+}
+
+// Source is a set of stream processing steps that has one open output.
+type Source[T any] interface {
+	Outlet[T]
+	To(Sink[T])
+}
+
+// Flow is a set of stream processing steps that has one open input of type In
+// and one open output of type Out.
+type Flow[In, Out any] interface {
+	Inlet[In]
+	Outlet[Out]
+	To(Sink[Out])
+}
+
+// Sink is a set of stream processing steps that has one open input.
+type Sink[T any] interface {
+	Inlet[T]
+}
+
+// Via streams data from the outlet through the given flow and returns the flow.
+// Go methods cannot declare type parameters, so unlike tombstreams.Flow.Via
+// this is a function rather than a method.
+func Via[T, U any](outlet Outlet[T], flow Flow[T, U]) Flow[T, U] {
+	DoStream[T](outlet, flow)
+	return flow
+}
+
+// To streams data from the outlet to the given sink.
+// It blocks until the outlet is closed or the tomb is dying.
+func To[T any](outlet Outlet[T], sink Sink[T]) {
+	transmit[T](outlet, sink)
+}
+
+// DoStream streams data from the outlet to inlet.
+func DoStream[T any](outlet Outlet[T], inlet Inlet[T]) {
+	t := outlet.Tomb()
+	if !t.Alive() {
+		return
+	}
+	t.Go(func() error {
+		transmit(outlet, inlet)
+		return nil
+	})
+}
+
+func transmit[T any](outlet Outlet[T], inlet Inlet[T]) {
+	t := outlet.Tomb()
+	defer close(inlet.In())
+	for {
+		var e T
+		select {
+		case elem, ok := <-outlet.Out():
+			if ok {
+				e = elem
+			} else {
+				return
+			}
+		case <-t.Dying():
+			return
+		}
+		select {
+		case inlet.In() <- e:
+		case <-t.Dying():
+			return
+		}
+	}
+}
+
+// Typed converts an interface{} outlet into a typed flow.
+// An element that is not a T kills the tomb with an error.
+// Cancelling the flow, if it is Cancellable, cancels the outlet.
+func Typed[T any](outlet tombstreams.Outlet) *PassThrough[T] {
+	t := outlet.Tomb()
+	pt := NewPassThrough[T](t)
+	if t.Alive() {
+		t.Go(func() error {
+			defer close(pt.In())
+			cancelled := cancelledOf(pt)
+			for {
+				var e T
+				select {
+				case elem, ok := <-outlet.Out():
+					if !ok {
+						return nil
+					}
+					v, ok := elem.(T)
+					if !ok {
+						return fmt.Errorf("typed: unexpected element type %T, want %T", elem, e)
+					}
+					e = v
+				case <-cancelled:
+					cancelUpstream(outlet)
+					return nil
+				case <-t.Dying():
+					return nil
+				}
+				select {
+				case pt.In() <- e:
+				case <-cancelled:
+					cancelUpstream(outlet)
+					return nil
+				case <-t.Dying():
+					return nil
+				}
+			}
+		})
+	}
+	return pt
+}
+
+// Untyped converts a typed outlet into an interface{} flow, so it can be
+// connected to the stages of the tombstreams package.
+// Cancelling the flow, such as with a Take, cancels the outlet if it is
+// tombstreams.Cancellable.
+func Untyped[T any](outlet Outlet[T]) *tombstreams.PassThrough {
+	t := outlet.Tomb()
+	pt := tombstreams.NewPassThrough(t)
+	if t.Alive() {
+		t.Go(func() error {
+			defer close(pt.In())
+			cancelled := cancelledOf(pt)
+			for {
+				var e interface{}
+				select {
+				case elem, ok := <-outlet.Out():
+					if ok {
+						e = elem
+					} else {
+						return nil
+					}
+				case <-cancelled:
+					cancelUpstream(outlet)
+					return nil
+				case <-t.Dying():
+					return nil
+				}
+				select {
+				case pt.In() <- e:
+				case <-cancelled:
+					cancelUpstream(outlet)
+					return nil
+				case <-t.Dying():
+					return nil
+				}
+			}
+		})
+	}
+	return pt
+}
+
+// cancelledOf returns the cancellation channel of x, or nil if x is not
+// tombstreams.Cancellable.
+func cancelledOf(x interface{}) <-chan struct{} {
+	if c, ok := x.(tombstreams.Cancellable); ok {
+		return c.Cancelled()
+	}
+	return nil
+}
+
+// cancelUpstream cancels x if it is tombstreams.Cancellable.
+func cancelUpstream(x interface{}) {
+	if c, ok := x.(tombstreams.Cancellable); ok {
+		c.Cancel()
+	}
+}
@@ -0,0 +1,153 @@
+package typed_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/tomb.v2"
+
+	"github.com/artificial-james/tombstreams"
+	"github.com/artificial-james/tombstreams/typed"
+)
+
+func generateCounter(ctx context.Context, count int) <-chan int {
+	out := make(chan int)
+
+	go func() {
+		defer close(out)
+		for i := 0; i < count; i++ {
+			select {
+			case out <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+func TestPipeline(t *testing.T) {
+	t.Run("Normal", func(t *testing.T) {
+		size := 4
+		filter := func(in int) (bool, error) {
+			return in%2 == 0, nil
+		}
+		mapp := func(in int) (string, error) {
+			return fmt.Sprintf("Test-%d", in), nil
+		}
+		flatten := func(in string) ([]string, error) {
+			return []string{in, strings.ToLower(in)}, nil
+		}
+
+		tb, ctx := tomb.WithContext(context.TODO())
+		source := typed.NewChanSource(tb, generateCounter(ctx, size))
+		filt := typed.NewFilter(tb, filter, 2)
+		mapper := typed.NewMap(tb, mapp, 2)
+		flat := typed.NewFlatMap(tb, flatten, 2)
+
+		out := make(chan string)
+		sink := typed.NewChanSink(out)
+
+		tb.Go(func() error {
+			typed.Via[string, string](typed.Via[int, string](typed.Via[int, int](source, filt), mapper), flat).To(sink)
+			return nil
+		})
+
+		actual := make([]string, 0, size)
+		for s := range sink.Out {
+			actual = append(actual, s)
+		}
+		<-tb.Dead()
+
+		assert.Equal(t, nil, tb.Err())
+		assert.Equal(t, context.Canceled, ctx.Err())
+		assert.ElementsMatch(t, []string{"Test-0", "test-0", "Test-2", "test-2"}, actual)
+	})
+	t.Run("Error", func(t *testing.T) {
+		size := 3
+		mapp := func(in int) (string, error) {
+			if in == 1 {
+				return "", fmt.Errorf("error!")
+			}
+			return fmt.Sprintf("Test-%d", in), nil
+		}
+
+		tb, ctx := tomb.WithContext(context.TODO())
+		source := typed.NewChanSource(tb, generateCounter(ctx, size))
+		mapper := typed.NewMap(tb, mapp, 2)
+		sink := typed.NewFuncSink(tb, func(string) error { return nil })
+
+		typed.Via[int, string](source, mapper).To(sink)
+
+		<-tb.Dead()
+
+		assert.EqualError(t, tb.Err(), "error!")
+		assert.Equal(t, context.Canceled, ctx.Err())
+	})
+}
+
+func TestInterop(t *testing.T) {
+	t.Run("Normal", func(t *testing.T) {
+		size := 3
+		mapp := func(in interface{}) (interface{}, error) {
+			return in.(int) * 10, nil
+		}
+
+		tb, ctx := tomb.WithContext(context.TODO())
+		source := typed.NewChanSource(tb, generateCounter(ctx, size))
+		mapper := tombstreams.NewMap(tb, mapp, 1)
+
+		out := make(chan int)
+		sink := typed.NewChanSink(out)
+
+		tb.Go(func() error {
+			typed.Typed[int](typed.Untyped[int](source).Via(mapper)).To(sink)
+			return nil
+		})
+
+		actual := make([]int, 0, size)
+		for i := range sink.Out {
+			actual = append(actual, i)
+		}
+		<-tb.Dead()
+
+		assert.Equal(t, nil, tb.Err())
+		assert.Equal(t, []int{0, 10, 20}, actual)
+	})
+	t.Run("Cancelled", func(t *testing.T) {
+		tb, ctx := tomb.WithContext(context.TODO())
+		source := typed.NewChanSource(tb, generateCounter(ctx, 1000))
+
+		out := make(chan interface{})
+		sink := tombstreams.NewChanSink(out)
+
+		tb.Go(func() error {
+			tombstreams.NewStream(typed.Untyped[int](source)).Take(3).To(sink)
+			return nil
+		})
+
+		actual := make([]interface{}, 0)
+		for e := range out {
+			actual = append(actual, e)
+		}
+		<-tb.Dead()
+
+		assert.Equal(t, nil, tb.Err())
+		assert.Equal(t, []interface{}{0, 1, 2}, actual)
+	})
+	t.Run("Type Mismatch", func(t *testing.T) {
+		tb, ctx := tomb.WithContext(context.TODO())
+		source := tombstreams.NewChanSource(tb, tombstreams.GenerateIDs(ctx, []string{"a", "b"}))
+		sink := typed.NewFuncSink(tb, func(int) error { return nil })
+
+		typed.Typed[int](source).To(sink)
+
+		<-tb.Dead()
+
+		assert.EqualError(t, tb.Err(), "typed: unexpected element type string, want int")
+	})
+}
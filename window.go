@@ -0,0 +1,249 @@
+package tombstreams
+
+import (
+	"time"
+
+	"gopkg.in/tomb.v2"
+)
+
+// TumblingWindow groups the incoming elements into fixed-size,
+// non-overlapping time windows and emits each window as an []interface{}.
+// Empty windows are not emitted.
+//
+// in  -- 1 -- 2 ---- 3 -- 4 ------ 5 --
+//        |    |      |    |        |
+//    [--- size ---][--- size ---][--- size ---]
+//              |             |             |
+// out -------- [1 2] ------- [3 4] ------- [5]
+type TumblingWindow struct {
+	stage
+	size time.Duration
+}
+
+// Verify TumblingWindow satisfies the Flow interface.
+var _ Flow = (*TumblingWindow)(nil)
+
+// NewTumblingWindow returns a new TumblingWindow instance.
+// size is the duration of every window, it must be positive.
+func NewTumblingWindow(t *tomb.Tomb, size time.Duration, opts ...Option) *TumblingWindow {
+	if size <= 0 {
+		panic("TumblingWindow size must be positive")
+	}
+	window := &TumblingWindow{
+		newStage(t, newOptions("TumblingWindow", opts)),
+		size,
+	}
+	if t.Alive() {
+		t.Go(window.doStream)
+	}
+	return window
+}
+
+func (tw *TumblingWindow) doStream() error {
+	defer close(tw.out)
+
+	ticker := time.NewTicker(tw.size)
+	defer ticker.Stop()
+
+	var buffer []interface{}
+	for {
+		select {
+		case elem, ok := <-tw.in:
+			if !ok {
+				tw.emitWindow(buffer)
+				return nil
+			}
+			buffer = append(buffer, elem)
+		case <-ticker.C:
+			if !tw.emitWindow(buffer) {
+				return nil
+			}
+			buffer = nil
+		case <-tw.t.Dying():
+			return nil
+		}
+	}
+}
+
+// SlidingWindow groups the incoming elements into overlapping time windows.
+// Every slide it emits the elements received during the last size as an
+// []interface{}, so an element appears in up to size/slide windows.
+// Empty windows are not emitted.
+//
+// in  -- 1 ------ 2 ---------- 3 ---------------
+//        |        |            |
+//   [------ size -------]
+//             [------ size -------]
+//                       [------ size -------]
+//                       |         |         |
+// out ----------------- [1 2] --- [2 3] --- [3] --
+type SlidingWindow struct {
+	stage
+	size  time.Duration
+	slide time.Duration
+}
+
+// Verify SlidingWindow satisfies the Flow interface.
+var _ Flow = (*SlidingWindow)(nil)
+
+// NewSlidingWindow returns a new SlidingWindow instance.
+// size is the duration of every window and slide is the interval at which
+// windows are emitted. slide must be positive and not larger than size.
+func NewSlidingWindow(t *tomb.Tomb, size, slide time.Duration, opts ...Option) *SlidingWindow {
+	if slide <= 0 {
+		panic("SlidingWindow slide must be positive")
+	}
+	if slide > size {
+		panic("SlidingWindow slide is larger than its size")
+	}
+	window := &SlidingWindow{
+		newStage(t, newOptions("SlidingWindow", opts)),
+		size,
+		slide,
+	}
+	if t.Alive() {
+		t.Go(window.doStream)
+	}
+	return window
+}
+
+type timedElement struct {
+	elem interface{}
+	at   time.Time
+}
+
+func (sw *SlidingWindow) doStream() error {
+	defer close(sw.out)
+
+	ticker := time.NewTicker(sw.slide)
+	defer ticker.Stop()
+
+	var buffer []timedElement
+	// fresh is set when an element arrived since the last emitted window,
+	// so the final window is not a copy of the previous one.
+	fresh := false
+	window := func() []interface{} {
+		elems := make([]interface{}, len(buffer))
+		for i, te := range buffer {
+			elems[i] = te.elem
+		}
+		return elems
+	}
+	for {
+		select {
+		case elem, ok := <-sw.in:
+			if !ok {
+				if fresh {
+					sw.emitWindow(window())
+				}
+				return nil
+			}
+			buffer = append(buffer, timedElement{elem, time.Now()})
+			fresh = true
+		case now := <-ticker.C:
+			// Evict the elements that fell out of the window ending now.
+			start := now.Add(-sw.size)
+			i := 0
+			for i < len(buffer) && !buffer[i].at.After(start) {
+				i++
+			}
+			buffer = buffer[i:]
+			if len(buffer) == 0 {
+				continue
+			}
+			if !sw.emitWindow(window()) {
+				return nil
+			}
+			fresh = false
+		case <-sw.t.Dying():
+			return nil
+		}
+	}
+}
+
+// SessionWindow groups the incoming elements into sessions of activity.
+// A session is emitted as an []interface{} once no element has been received
+// for the inactivity gap.
+//
+// in  -- 1 -- 2 ------------- 3 -- 4 ------------- 5 --
+//        |    |               |    |               |
+//    [------- gap ---]  [--------- gap ---]  [--- gap ---]
+//                    |                    |              |
+// out -------------- [1 2] -------------- [3 4] -------- [5]
+type SessionWindow struct {
+	stage
+	gap time.Duration
+}
+
+// Verify SessionWindow satisfies the Flow interface.
+var _ Flow = (*SessionWindow)(nil)
+
+// NewSessionWindow returns a new SessionWindow instance.
+// gap is the inactivity duration that closes a session, it must be positive.
+func NewSessionWindow(t *tomb.Tomb, gap time.Duration, opts ...Option) *SessionWindow {
+	if gap <= 0 {
+		panic("SessionWindow gap must be positive")
+	}
+	window := &SessionWindow{
+		newStage(t, newOptions("SessionWindow", opts)),
+		gap,
+	}
+	if t.Alive() {
+		t.Go(window.doStream)
+	}
+	return window
+}
+
+func (sw *SessionWindow) doStream() error {
+	defer close(sw.out)
+
+	var timer *time.Timer
+	var expired <-chan time.Time
+	defer func() {
+		if timer != nil {
+			timer.Stop()
+		}
+	}()
+
+	var buffer []interface{}
+	for {
+		select {
+		case elem, ok := <-sw.in:
+			if !ok {
+				sw.emitWindow(buffer)
+				return nil
+			}
+			buffer = append(buffer, elem)
+			if timer == nil {
+				timer = time.NewTimer(sw.gap)
+			} else {
+				if !timer.Stop() {
+					// Drain a tick that fired but was not received.
+					select {
+					case <-timer.C:
+					default:
+					}
+				}
+				timer.Reset(sw.gap)
+			}
+			expired = timer.C
+		case <-expired:
+			expired = nil
+			if !sw.emitWindow(buffer) {
+				return nil
+			}
+			buffer = nil
+		case <-sw.t.Dying():
+			return nil
+		}
+	}
+}
+
+// emitWindow emits a non-empty window.
+// It returns false if the stage must stop.
+func (s *stage) emitWindow(window []interface{}) bool {
+	if len(window) == 0 {
+		return true
+	}
+	return s.emit(window)
+}